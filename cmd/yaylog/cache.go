@@ -0,0 +1,50 @@
+package main
+
+import (
+	"errors"
+	"yaylog/internal/config"
+	out "yaylog/internal/display"
+	"yaylog/internal/pkgdata"
+)
+
+func runCacheCommand(cfg config.Config) error {
+	if len(cfg.CommandArgs) > 0 && cfg.CommandArgs[0] == config.CacheClear {
+		return clearCache()
+	}
+
+	info, err := pkgdata.GetCacheInfo()
+
+	var status string
+	switch {
+	case errors.Is(err, pkgdata.ErrCacheMissing):
+		status = out.CacheMissing
+	case errors.Is(err, pkgdata.ErrCacheVersion):
+		status = out.CacheOutdated
+	case errors.Is(err, pkgdata.ErrCacheCorrupt):
+		status = out.CacheCorrupt
+	case err != nil:
+		return err
+	case info.IsStale:
+		status = out.CacheStale
+	default:
+		status = out.CacheCurrent
+	}
+
+	out.RenderCacheInfo(info, status, cfg.OutputJson, cfg.Location)
+	return nil
+}
+
+func clearCache() error {
+	removed, err := pkgdata.ClearProtoCache()
+	if err != nil {
+		return err
+	}
+
+	if !removed {
+		out.WriteLine("No cache to remove.")
+		return nil
+	}
+
+	out.WriteLine("Removed the package cache.")
+	return nil
+}
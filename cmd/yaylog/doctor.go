@@ -0,0 +1,24 @@
+package main
+
+import (
+	"fmt"
+	"yaylog/internal/config"
+	out "yaylog/internal/display"
+	"yaylog/internal/pkgdata"
+)
+
+// checks every database entry, the cache is neither read nor updated
+func runDoctor(cfg config.Config) error {
+	diagnostics, checked, err := pkgdata.CheckDatabase()
+	if err != nil {
+		return fmt.Errorf("Error: %w", err)
+	}
+
+	out.RenderDiagnostics(diagnostics, checked, cfg.OutputJson, cfg.HasNoHeaders)
+
+	if cfg.Strict && len(diagnostics) > 0 {
+		return fmt.Errorf("Error: %w: %d found", pkgdata.ErrMalformedEntries, len(diagnostics))
+	}
+
+	return nil
+}
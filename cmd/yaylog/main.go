@@ -1,8 +1,10 @@
 package main
 
 import (
+	"errors"
 	"os"
 	"sync"
+	"time"
 	"yaylog/internal/config"
 	out "yaylog/internal/display"
 	"yaylog/internal/pipeline/meta"
@@ -17,7 +19,13 @@ func main() {
 	err := mainWithConfig(&config.CliConfigProvider{})
 	if err != nil {
 		out.WriteLine(err.Error() + "\n")
-		pflag.PrintDefaults()
+
+		// the usage does not help with a broken or busy package database
+		if !errors.Is(err, pkgdata.ErrMalformedEntries) && !errors.Is(err, pkgdata.ErrDbLocked) &&
+			!errors.Is(err, pkgdata.ErrDbUnreadable) {
+			pflag.PrintDefaults()
+		}
+
 		os.Exit(1)
 	}
 }
@@ -28,18 +36,43 @@ func mainWithConfig(configProvider config.ConfigProvider) error {
 		return err
 	}
 
+	// one timezone for every date that is parsed, matched or shown, see --tz
+	if cfg.Location == nil {
+		cfg.Location = time.Local
+	}
+
+	out.SetSizeUnits(cfg.SizeUnits)
+
+	// these commands only read recorded snapshots or the cache, not the installed packages
+	if cfg.Command == config.CommandSnapshot && len(cfg.CommandArgs) > 0 {
+		return listSnapshots(cfg)
+	}
+
+	if cfg.Command == config.CommandTrend {
+		return showTrend(cfg)
+	}
+
+	if cfg.Command == config.CommandCache {
+		return runCacheCommand(cfg)
+	}
+
+	if cfg.Command == config.CommandDoctor {
+		return runDoctor(cfg)
+	}
+
 	isInteractive := term.IsTerminal(int(os.Stdout.Fd())) && !cfg.DisableProgress
 	pipelineCtx := &meta.PipelineContext{IsInteractive: isInteractive}
 	var wg sync.WaitGroup
 
-	pipelinePhases := []phasekit.PipelinePhase{
-		phasekit.New("Loading cache", phasekit.LoadCacheStep, &wg),
-		phasekit.New("Fetching packages", phasekit.FetchStep, &wg),
-		phasekit.New("Calculating reverse dependencies", phasekit.ReverseDepStep, &wg),
-		phasekit.New("Saving cache", phasekit.SaveCacheStep, &wg),
-		phasekit.New("Filtering", phasekit.FilterStep, &wg),
-		phasekit.New("Sorting", phasekit.SortStep, &wg),
-	}
+	// a cache that is completed in the background is saved after the output was written.
+	// the output shows sooner, but exiting still waits for the save
+	defer func() {
+		if pipelineCtx.FinishCache != nil {
+			pipelineCtx.FinishCache()
+		}
+	}()
+
+	pipelinePhases := buildPipelinePhases(cfg, &wg)
 
 	var pkgPtrs []*pkgdata.PkgInfo
 	for i, phase := range pipelinePhases {
@@ -50,20 +83,73 @@ func mainWithConfig(configProvider config.ConfigProvider) error {
 
 		if i > 0 && len(pkgPtrs) == 0 { // only start checking once both fetche
 			out.WriteLine("No packages to display.")
+
+			for _, suggestion := range pipelineCtx.Suggestions {
+				out.WriteLine(suggestion)
+			}
+
 			return nil
 		}
 	}
 
+	switch cfg.Command {
+	case config.CommandSnapshot:
+		return recordSnapshot(pkgPtrs, pipelineCtx)
+	case config.CommandDiff:
+		return diffSnapshots(pkgPtrs, cfg)
+	}
+
 	pkgPtrs = trimPackagesLen(pkgPtrs, cfg)
 	renderOutput(pkgPtrs, cfg)
 
 	return nil
 }
 
+func buildPipelinePhases(cfg config.Config, wg *sync.WaitGroup) []phasekit.PipelinePhase {
+	var sourcePhases []phasekit.PipelinePhase
+
+	if cfg.ShowRemoved {
+		sourcePhases = []phasekit.PipelinePhase{
+			phasekit.New("Reading removed packages", phasekit.RemovedPkgsStep, wg),
+		}
+	} else {
+		sourcePhases = []phasekit.PipelinePhase{
+			phasekit.New("Loading cache", phasekit.LoadCacheStep, wg),
+			phasekit.New("Fetching packages", phasekit.FetchStep, wg),
+			phasekit.New("Calculating reverse dependencies", phasekit.ReverseDepStep, wg),
+			phasekit.New("Saving cache", phasekit.SaveCacheStep, wg),
+			phasekit.New("Recording snapshot", phasekit.SnapshotStep, wg),
+			phasekit.New("Reconstructing past packages", phasekit.AsOfStep, wg),
+			phasekit.New("Reading package history", phasekit.HistoryStep, wg),
+			phasekit.New("Grouping install sessions", phasekit.SessionStep, wg),
+			phasekit.New("Calculating dependency overlap", phasekit.DependencyOverlapStep, wg),
+			phasekit.New("Checking install reasons", phasekit.SuggestReasonStep, wg),
+		}
+	}
+
+	// filters see every package, session and transitive queries resolve against the whole set
+	return append(
+		sourcePhases,
+		phasekit.New("Filtering", phasekit.FilterStep, wg),
+		phasekit.New("Searching", phasekit.SearchStep, wg),
+		phasekit.New("Sorting", phasekit.SortStep, wg),
+	)
+}
+
 func trimPackagesLen(
 	pkgPtrs []*pkgdata.PkgInfo,
 	cfg config.Config,
 ) []*pkgdata.PkgInfo {
+	// suggestions are meant to be reviewed and scripted as a whole
+	if cfg.Command == config.CommandSuggest {
+		return pkgPtrs
+	}
+
+	// when grouping, the limit applies to the number of sessions
+	if cfg.GroupBy == config.GroupBySession && cfg.Count > 0 && !cfg.AllPackages {
+		return pkgdata.LatestSessions(pkgPtrs, cfg.Count)
+	}
+
 	if cfg.Count > 0 && !cfg.AllPackages && len(pkgPtrs) > cfg.Count {
 		cutoffIdx := len(pkgPtrs) - cfg.Count
 		pkgPtrs = pkgPtrs[cutoffIdx:]
@@ -73,10 +159,25 @@ func trimPackagesLen(
 }
 
 func renderOutput(pkgs []*pkgdata.PkgInfo, cfg config.Config) {
+	switch cfg.Command {
+	case config.CommandOverlap:
+		out.RenderOverlapMatrix(pkgdata.BuildOverlapMatrix(pkgs), cfg.OutputJson, cfg.HasNoHeaders)
+		return
+	case config.CommandSuggest:
+		out.RenderReasonSuggestions(pkgs, cfg.OutputJson, cfg.OutputScript, cfg.HasNoHeaders)
+		return
+	}
+
+	if cfg.GroupBy == config.GroupBySession {
+		sessions := pkgdata.GroupSessions(pkgs)
+		out.RenderSessions(sessions, cfg.Fields, cfg.OutputJson, cfg.ShowFullTimestamp, cfg.HasNoHeaders, cfg.Location)
+		return
+	}
+
 	if cfg.OutputJson {
-		out.RenderJson(pkgs, cfg.Fields)
+		out.RenderJson(pkgs, cfg.Fields, cfg.Location)
 		return
 	}
 
-	out.RenderTable(pkgs, cfg.Fields, cfg.ShowFullTimestamp, cfg.HasNoHeaders)
+	out.RenderTable(pkgs, cfg.Fields, cfg.ShowFullTimestamp, cfg.HasNoHeaders, cfg.Location)
 }
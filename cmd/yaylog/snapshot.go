@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"time"
+	"yaylog/internal/config"
+	"yaylog/internal/consts"
+	out "yaylog/internal/display"
+	"yaylog/internal/pipeline/meta"
+	"yaylog/internal/pkgdata"
+)
+
+func listSnapshots(cfg config.Config) error {
+	snapshots, err := pkgdata.LoadSnapshots()
+	if err != nil {
+		return err
+	}
+
+	out.RenderSnapshotList(snapshots, cfg.OutputJson, cfg.HasNoHeaders, cfg.Location)
+	return nil
+}
+
+// months by default, the period is the only argument
+func showTrend(cfg config.Config) error {
+	period := consts.TrendMonth
+	if len(cfg.CommandArgs) > 0 {
+		period = cfg.CommandArgs[0]
+	}
+
+	snapshots, err := pkgdata.LoadSnapshots()
+	if err != nil {
+		return err
+	}
+
+	out.RenderTrend(pkgdata.BuildTrend(snapshots, period, cfg.Location), cfg.OutputJson, cfg.HasNoHeaders)
+	return nil
+}
+
+func recordSnapshot(pkgPtrs []*pkgdata.PkgInfo, pipelineCtx *meta.PipelineContext) error {
+	if pipelineCtx.RacedRead {
+		return fmt.Errorf("Error: Snapshot not recorded, %w or changed while it was read", pkgdata.ErrDbLocked)
+	}
+
+	snapshot, recorded, err := pkgdata.SaveSnapshot(pkgPtrs, time.Now().Unix())
+	if err != nil {
+		return err
+	}
+
+	out.ClearProgress()
+
+	if !recorded {
+		out.WriteLine(fmt.Sprintf("Packages unchanged since snapshot %d, nothing recorded.", snapshot.Id))
+		return nil
+	}
+
+	out.WriteLine(fmt.Sprintf("Recorded snapshot %d (%d packages).", snapshot.Id, len(snapshot.Pkgs)))
+	return nil
+}
+
+// compares the last snapshot with the installed packages unless references are given
+func diffSnapshots(pkgPtrs []*pkgdata.PkgInfo, cfg config.Config) error {
+	fromRef := pkgdata.SnapshotRefLatest
+	toRef := pkgdata.SnapshotRefCurrent
+
+	if len(cfg.CommandArgs) > 0 {
+		fromRef = cfg.CommandArgs[0]
+	}
+
+	if len(cfg.CommandArgs) > 1 {
+		toRef = cfg.CommandArgs[1]
+	}
+
+	snapshots, err := pkgdata.LoadSnapshots()
+	if err != nil {
+		return err
+	}
+
+	current := &pkgdata.Snapshot{Timestamp: time.Now().Unix(), Pkgs: pkgPtrs}
+
+	from, err := resolveSnapshotRef(snapshots, fromRef, current, cfg.Location)
+	if err != nil {
+		return err
+	}
+
+	to, err := resolveSnapshotRef(snapshots, toRef, current, cfg.Location)
+	if err != nil {
+		return err
+	}
+
+	changes := pkgdata.DiffPackages(from.Pkgs, to.Pkgs)
+	out.RenderSnapshotDiff(from, to, changes, cfg.OutputJson, cfg.HasNoHeaders, cfg.Location)
+
+	return nil
+}
+
+func resolveSnapshotRef(
+	snapshots []*pkgdata.Snapshot,
+	ref string,
+	current *pkgdata.Snapshot,
+	location *time.Location,
+) (*pkgdata.Snapshot, error) {
+	if ref == pkgdata.SnapshotRefCurrent {
+		return current, nil
+	}
+
+	snapshot, err := pkgdata.ResolveSnapshot(snapshots, ref, location)
+	if err != nil {
+		return nil, fmt.Errorf("Error: %v", err)
+	}
+
+	return snapshot, nil
+}
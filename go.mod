@@ -5,9 +5,7 @@ go 1.23.2
 require (
 	github.com/spf13/pflag v1.0.5
 	golang.org/x/term v0.28.0
+	google.golang.org/protobuf v1.36.6
 )
 
-require (
-	golang.org/x/sys v0.29.0 // indirect
-	google.golang.org/protobuf v1.36.6 // indirect
-)
+require golang.org/x/sys v0.29.0 // indirect
@@ -0,0 +1,21 @@
+package config
+
+import (
+	"fmt"
+	"time"
+	"yaylog/internal/timeexpr"
+)
+
+// dates refer to the start of that day, month or year
+func parseAsOf(asOfInput string, location *time.Location) (int64, error) {
+	if asOfInput == "" {
+		return 0, nil
+	}
+
+	span, err := timeexpr.Parse(asOfInput, time.Now(), location)
+	if err != nil {
+		return 0, fmt.Errorf("Error: invalid --as-of date: %q. Use %s", asOfInput, timeexpr.ExpressionHelp)
+	}
+
+	return span.Start.Unix(), nil
+}
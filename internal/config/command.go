@@ -0,0 +1,68 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"yaylog/internal/consts"
+)
+
+const (
+	CommandOverlap  = "overlap"
+	CommandSuggest  = "suggest"
+	CommandSnapshot = "snapshot"
+	CommandDiff     = "diff"
+	CommandTrend    = "trend"
+	CommandCache    = "cache"
+	CommandDoctor   = "doctor"
+
+	SnapshotList = "list"
+	CacheClear   = "clear"
+)
+
+// maximum number of arguments each command accepts
+var validCommands = map[string]int{
+	CommandOverlap:  0,
+	CommandSuggest:  0,
+	CommandSnapshot: 1,
+	CommandDiff:     2,
+	CommandTrend:    1,
+	CommandCache:    1,
+	CommandDoctor:   0,
+}
+
+// commands whose argument is one of a fixed set of choices
+var commandChoices = map[string][]string{
+	CommandSnapshot: {SnapshotList},
+	CommandTrend:    {consts.TrendDay, consts.TrendWeek, consts.TrendMonth},
+	CommandCache:    {CacheClear},
+}
+
+func parseCommand(args []string) (string, []string, error) {
+	if len(args) == 0 {
+		return "", nil, nil
+	}
+
+	command := strings.ToLower(args[0])
+	maxArgs, exists := validCommands[command]
+	if !exists {
+		return "", nil, fmt.Errorf("Error: unknown command: %s", args[0])
+	}
+
+	commandArgs := args[1:]
+	if len(commandArgs) > maxArgs {
+		return "", nil, fmt.Errorf("Error: unexpected arguments for %s: %s", command, strings.Join(commandArgs[maxArgs:], " "))
+	}
+
+	choices, hasChoices := commandChoices[command]
+	if hasChoices && len(commandArgs) == 1 && !slices.Contains(choices, commandArgs[0]) {
+		return "", nil, fmt.Errorf(
+			"Error: invalid argument for %s: %s. Expected one of: %s",
+			command,
+			commandArgs[0],
+			strings.Join(choices, ", "),
+		)
+	}
+
+	return command, commandArgs, nil
+}
@@ -3,8 +3,8 @@ package config
 import (
 	"fmt"
 	"os"
-	"regexp"
 	"strings"
+	"time"
 	"yaylog/internal/consts"
 
 	"github.com/spf13/pflag"
@@ -13,19 +13,40 @@ import (
 const (
 	ReasonExplicit   = "explicit"
 	ReasonDependency = "dependency"
+	GroupBySession   = "session"
+
+	OnLockWait    = "wait"
+	OnLockWarn    = "warn"
+	OnLockProceed = "proceed"
 )
 
 type Config struct {
+	Command           string
+	CommandArgs       []string
 	Count             int
 	AllPackages       bool
 	ShowHelp          bool
 	OutputJson        bool
+	OutputScript      bool
 	HasNoHeaders      bool
 	ShowFullTimestamp bool
 	DisableProgress   bool
+	ShowRemoved       bool
+	RecordSnapshot    bool
+	NoCache           bool
+	RebuildCache      bool
+	Strict            bool
+	OnLock            string
+	LockTimeout       time.Duration
+	AsOf              int64
+	GroupBy           string
 	SortOption        SortOption
 	Fields            []consts.FieldType
-	FilterQueries     map[consts.FieldType]string
+	FilterQuery       *Query // nil without --where
+	CaseSensitive     bool
+	SearchTerms       []string       // from --search, every term has to match
+	Location          *time.Location // dates are parsed, matched and shown in this timezone
+	SizeUnits         string
 }
 
 type SortOption struct {
@@ -60,18 +81,33 @@ func ParseFlags(args []string) (Config, error) {
 	var hasAllFields bool
 	var showHelp bool
 	var outputJson bool
+	var outputScript bool
 	var hasNoHeaders bool
 	var showFullTimestamp bool
 	var disableProgress bool
+	var showRemoved bool
+	var recordSnapshot bool
+	var noCache bool
+	var rebuildCache bool
+	var strict bool
+	var caseSensitive bool
+	var useUtc bool
+	var lockTimeout time.Duration
 	var explicitOnly bool
 	var dependenciesOnly bool
 
 	var filterInputs []string
+	var searchInput string
 	var dateFilter string
 	var sizeFilter string
 	var nameFilter string
 	var requiredByFilter string
 	var sortInput string
+	var asOfInput string
+	var groupBy string
+	var onLock string
+	var timeZone string
+	var sizeUnits string
 	var fieldInput string
 	var addFieldInput string
 
@@ -81,7 +117,12 @@ func ParseFlags(args []string) (Config, error) {
 	pflag.BoolVarP(&allPackages, "all", "a", false, "Show all packages (ignores -l)")
 
 	pflag.StringArrayVarP(&filterInputs, "where", "w", []string{}, "Apply multiple filters (e.g. --where size=2KB:3KB -wname=vim)")
+	pflag.StringVarP(&searchInput, "search", "", "", "Search names and descriptions for all given terms, ranked by relevance")
+	pflag.BoolVarP(&caseSensitive, "case-sensitive", "", false, "Match text in queries case-sensitively")
 	pflag.StringVarP(&sortInput, "order", "O", "date", "Order results by field")
+	pflag.StringVarP(&groupBy, "group-by", "", "", "Group results, only 'session' is supported")
+	pflag.BoolVarP(&showRemoved, "removed", "", false, "Show removed packages from pacman.log instead of installed packages")
+	pflag.StringVarP(&asOfInput, "as-of", "", "", "Show packages as they were installed at a past date, reconstructed from pacman.log")
 
 	pflag.BoolVarP(&hasNoHeaders, "no-headers", "", false, "Hide headers for table ouput (useful for scripts/automation)")
 	pflag.BoolVarP(&hasAllFields, "select-all", "A", false, "Display all available fields")
@@ -89,8 +130,18 @@ func ParseFlags(args []string) (Config, error) {
 	pflag.StringVarP(&addFieldInput, "select-add", "S", "", "Add fields to the default output")
 
 	pflag.BoolVarP(&showFullTimestamp, "full-timestamp", "", false, "Show full timestamp instead of just the date")
+	pflag.StringVarP(&timeZone, "tz", "", "", "Parse, match and show dates in this IANA timezone, e.g. Europe/Berlin (default: local time)")
+	pflag.BoolVarP(&useUtc, "utc", "", false, "Parse, match and show dates in UTC")
+	pflag.StringVarP(&sizeUnits, "size-units", "", consts.SizeUnitsIec, "Show sizes in binary 'iec' units (KiB, MiB) or decimal 'si' units (kB, MB)")
 	pflag.BoolVarP(&outputJson, "json", "", false, "Output results in JSON format")
+	pflag.BoolVarP(&outputScript, "script", "", false, "Output suggestions as a pacman script for review (suggest only)")
 	pflag.BoolVarP(&disableProgress, "no-progress", "", false, "Force suppress progress output")
+	pflag.BoolVarP(&recordSnapshot, "snapshot", "", false, "Record a snapshot of the package set for later diffs")
+	pflag.BoolVarP(&noCache, "no-cache", "", false, "Read the package database without using or updating the cache")
+	pflag.BoolVarP(&rebuildCache, "rebuild-cache", "", false, "Ignore the existing cache and rebuild it from the package database")
+	pflag.BoolVarP(&strict, "strict", "", false, "Fail instead of skipping malformed package database entries")
+	pflag.StringVarP(&onLock, "on-lock", "", OnLockWarn, "What to do while pacman holds the database lock: 'wait', 'warn' or 'proceed'")
+	pflag.DurationVarP(&lockTimeout, "lock-timeout", "", 30*time.Second, "How long to wait for the database lock with --on-lock=wait")
 
 	pflag.BoolVarP(&showHelp, "help", "h", false, "Display help")
 
@@ -105,7 +156,7 @@ func ParseFlags(args []string) (Config, error) {
 	pflag.BoolVarP(&dependenciesOnly, "dependencies", "d", false, "Show only packages installed as dependencies")
 	pflag.StringVar(&dateFilter, "date", "", "Filter packages by installation date. Supports exact dates (YYYY-MM-DD), ranges (YYYY-MM-DD:YYYY-MM-DD), and open-ended filters (:YYYY-MM-DD or YYYY-MM-DD:).")
 	pflag.StringVar(&sizeFilter, "size", "", "Filter packages by size. Supports ranges (e.g., 10MB:20GB), exact matches (e.g., 5MB), and open-ended values (e.g., :2GB or 500KB:)")
-	pflag.StringVar(&nameFilter, "name", "", "Filter packages by name (or similar name)")
+	pflag.StringVar(&nameFilter, "name", "", "Filter packages by name (substring match, use --where name~=... for similar names)")
 	pflag.StringVar(&requiredByFilter, "required-by", "", "Show only packages that are required by the specified package")
 
 	_ = pflag.CommandLine.MarkHidden("number")
@@ -140,7 +191,51 @@ func ParseFlags(args []string) (Config, error) {
 		count = 0
 	}
 
-	fieldsParsed, err := parseFields(fieldInput, addFieldInput, hasAllFields)
+	command, commandArgs, err := parseCommand(pflag.Args())
+	if err != nil {
+		return Config{}, err
+	}
+
+	if err = validateSizeUnits(sizeUnits); err != nil {
+		return Config{}, err
+	}
+
+	location, err := parseLocation(timeZone, useUtc)
+	if err != nil {
+		return Config{}, err
+	}
+
+	asOf, err := parseAsOf(asOfInput, location)
+	if err != nil {
+		return Config{}, err
+	}
+
+	if err = validateCommandFlags(command, outputJson, outputScript, showRemoved, recordSnapshot, asOf); err != nil {
+		return Config{}, err
+	}
+
+	if err = validateCacheFlags(command, noCache, rebuildCache); err != nil {
+		return Config{}, err
+	}
+
+	if err = validateStrict(command, strict, showRemoved); err != nil {
+		return Config{}, err
+	}
+
+	if err = validateOnLock(onLock, lockTimeout); err != nil {
+		return Config{}, err
+	}
+
+	if err = validateGroupBy(groupBy, command, showRemoved); err != nil {
+		return Config{}, err
+	}
+
+	defaultFields := consts.DefaultFields
+	if showRemoved {
+		defaultFields = consts.RemovedDefaultFields
+	}
+
+	fieldsParsed, err := parseFields(fieldInput, addFieldInput, hasAllFields, defaultFields)
 	if err != nil {
 		return Config{}, err
 	}
@@ -150,13 +245,23 @@ func ParseFlags(args []string) (Config, error) {
 		return Config{}, err
 	}
 
-	filterQueries, err := parseFilterQueries(filterInputs)
+	searchTerms := strings.Fields(searchInput)
+	if err = validateSearch(command, searchTerms, sortOption, fieldsParsed); err != nil {
+		return Config{}, err
+	}
+
+	// search results are ranked unless another order is asked for
+	if len(searchTerms) > 0 && !pflag.CommandLine.Changed("order") && !pflag.CommandLine.Changed("sort") {
+		sortOption = SortOption{Field: consts.FieldRelevance, Asc: false}
+	}
+
+	filterQuery, err := parseFilterQueries(filterInputs)
 	if err != nil {
 		return Config{}, err
 	}
 
-	filterQueries = convertLegacyFilters(
-		filterQueries,
+	filterQuery = convertLegacyFilters(
+		filterQuery,
 		dateFilter,
 		nameFilter,
 		sizeFilter,
@@ -165,22 +270,60 @@ func ParseFlags(args []string) (Config, error) {
 		dependenciesOnly,
 	)
 
+	if err = validateCommandQueries(command, filterQuery); err != nil {
+		return Config{}, err
+	}
+
 	cfg := Config{
+		Command:           command,
+		CommandArgs:       commandArgs,
 		Count:             count,
 		AllPackages:       allPackages,
 		ShowHelp:          showHelp,
 		OutputJson:        outputJson,
+		OutputScript:      outputScript,
 		HasNoHeaders:      hasNoHeaders,
 		ShowFullTimestamp: showFullTimestamp,
 		DisableProgress:   disableProgress,
+		ShowRemoved:       showRemoved,
+		RecordSnapshot:    recordSnapshot,
+		NoCache:           noCache,
+		RebuildCache:      rebuildCache,
+		Strict:            strict,
+		OnLock:            onLock,
+		LockTimeout:       lockTimeout,
+		AsOf:              asOf,
+		GroupBy:           groupBy,
 		SortOption:        sortOption,
 		Fields:            fieldsParsed,
-		FilterQueries:     filterQueries,
+		FilterQuery:       filterQuery,
+		CaseSensitive:     caseSensitive,
+		SearchTerms:       searchTerms,
+		Location:          location,
+		SizeUnits:         sizeUnits,
 	}
 
 	return cfg, nil
 }
 
+func parseLocation(timeZone string, useUtc bool) (*time.Location, error) {
+	switch {
+	case useUtc && timeZone != "":
+		return nil, fmt.Errorf("Error: cannot use --tz and --utc at the same time")
+	case useUtc:
+		return time.UTC, nil
+	case timeZone == "":
+		return time.Local, nil
+	}
+
+	location, err := time.LoadLocation(timeZone)
+	if err != nil {
+		return nil, fmt.Errorf("Error: invalid --tz timezone: %q. Use an IANA name such as Europe/Berlin or UTC", timeZone)
+	}
+
+	return location, nil
+}
+
 func parseSortOption(sortInput string) (SortOption, error) {
 	parts := strings.Split(sortInput, ":")
 	fieldKey := strings.ToLower(parts[0])
@@ -207,64 +350,56 @@ func parseSortOption(sortInput string) (SortOption, error) {
 	}, nil
 }
 
-func parseFilterQueries(filterInputs []string) (map[consts.FieldType]string, error) {
-	filterQueries := make(map[consts.FieldType]string)
-	filterRegex := regexp.MustCompile(`^([a-zA-Z0-9_-]+)=(.+)$`)
+// every --where is a query of its own, they are and'ed together
+func parseFilterQueries(filterInputs []string) (*Query, error) {
+	var filterQuery *Query
 
 	for _, input := range filterInputs {
-		matches := filterRegex.FindStringSubmatch(input)
-		if matches == nil {
-			return nil, fmt.Errorf("Invalid filter format: %q. Must be in form field=value", input)
-		}
-
-		field, value := matches[1], matches[2]
-		fieldType, exists := consts.FieldTypeLookup[field]
-		if !exists {
-			return nil, fmt.Errorf("Unknown filter field: %s", field)
+		query, err := parseQuery(input)
+		if err != nil {
+			return nil, err
 		}
 
-		if fieldType == consts.FieldReason && value != ReasonExplicit && value != ReasonDependency {
-			return nil, fmt.Errorf("Invalid reason filter value: %s. Allowed values are 'explicit' or 'dependency'", value)
-		}
-
-		filterQueries[fieldType] = value
+		filterQuery = andQueries(filterQuery, query)
 	}
 
-	return filterQueries, nil
+	return filterQuery, nil
 }
 
 func convertLegacyFilters(
-	filterQueries map[consts.FieldType]string,
+	filterQuery *Query,
 	dateFilter string,
 	nameFilter string,
 	sizeFilter string,
 	requiredByFilter string,
 	explicitOnly bool,
 	dependenciesOnly bool,
-) map[consts.FieldType]string {
+) *Query {
+	var legacyQueries []*Query
+
 	if dateFilter != "" {
-		filterQueries[consts.FieldDate] = dateFilter
+		legacyQueries = append(legacyQueries, newMatchQuery(consts.FieldDate, dateFilter))
 	}
 
 	if nameFilter != "" {
-		filterQueries[consts.FieldName] = nameFilter
+		legacyQueries = append(legacyQueries, newMatchQuery(consts.FieldName, nameFilter))
 	}
 
 	if sizeFilter != "" {
-		filterQueries[consts.FieldSize] = sizeFilter
+		legacyQueries = append(legacyQueries, newMatchQuery(consts.FieldSize, sizeFilter))
 	}
 
 	if requiredByFilter != "" {
-		filterQueries[consts.FieldRequiredBy] = requiredByFilter
+		legacyQueries = append(legacyQueries, newMatchQuery(consts.FieldRequiredBy, requiredByFilter))
 	}
 
 	if explicitOnly {
-		filterQueries[consts.FieldReason] = ReasonExplicit
+		legacyQueries = append(legacyQueries, newMatchQuery(consts.FieldReason, ReasonExplicit))
 	}
 
 	if dependenciesOnly {
-		filterQueries[consts.FieldReason] = ReasonDependency
+		legacyQueries = append(legacyQueries, newMatchQuery(consts.FieldReason, ReasonDependency))
 	}
 
-	return filterQueries
+	return andQueries(filterQuery, legacyQueries...)
 }
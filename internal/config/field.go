@@ -10,6 +10,7 @@ func parseFields(
 	fieldInput string,
 	addFieldInput string,
 	hasAllFields bool,
+	defaultFields []consts.FieldType,
 ) ([]consts.FieldType, error) {
 	var specifiedColumnsRaw string
 	var fields []consts.FieldType
@@ -24,7 +25,7 @@ func parseFields(
 		if hasAllFields {
 			fields = consts.ValidFields
 		} else {
-			fields = consts.DefaultFields
+			fields = defaultFields
 		}
 	}
 
@@ -7,31 +7,77 @@ import (
 )
 
 func PrintHelp() {
-	fmt.Println("Usage: yaylog [options]")
+	fmt.Println("Usage: yaylog [command] [options]")
+
+	fmt.Println("\nCommands:")
+	fmt.Println("  overlap                     Show a matrix of dependencies shared between explicitly installed packages")
+	fmt.Println("  suggest                     List packages whose install reason looks wrong (use --script for a pacman -D script)")
+	fmt.Println("  snapshot [list]             Record a snapshot of the installed packages, or list recorded snapshots")
+	fmt.Println("  diff [<from> [<to>]]        Show added, removed, upgraded and reason-changed packages between snapshots.")
+	fmt.Println("                               References are snapshot numbers, 'last', 'current', or dates (default: last current)")
+	fmt.Println("  cache [clear]               Show the cache location and status, or remove the cache")
+	fmt.Println("  doctor                      List malformed package database entries, which are left out of listings")
+	fmt.Println("  trend [day|week|month]      Show installed size and package count over time from snapshots (default: month)")
 
 	fmt.Println("\nOptions:")
 	pflag.PrintDefaults()
 
 	fmt.Println("\nQuerying Options:")
-	fmt.Println("  -w, --where <field>=<value> Apply queries to refine package listings. Can be used multiple times.")
-	fmt.Println("                               Example: --where size=100MB:1GB --where name=firefox")
+	fmt.Println("  -w, --where <query>         Apply queries to refine package listings. Can be used multiple times, all have to match.")
+	fmt.Println("                               Matches combine with and, or, not and parentheses. Quote values with spaces")
+	fmt.Println("                               Example: --where size=100MB:1GB --where 'name=python or name=perl'")
+	fmt.Println("                               Example: --where 'not (reason=explicit or arch=any)'")
 
 	fmt.Println("\n  Available queries:")
 	fmt.Println("    date=<YYYY-MM-DD>               Show packages installed on a specific date")
 	fmt.Println("    date=<YYYY-MM-DD>:              Show packages installed on or after the given date")
 	fmt.Println("    date=:<YYYY-MM-DD>              Show packages installed up to the given date")
 	fmt.Println("    date=<YYYY-MM-DD>:<YYYY-MM-DD>  Show packages installed in a date range")
+	fmt.Println("    date=2024-05, date=2024         Show packages installed in a whole month or year")
+	fmt.Println("    date=7d, date=7d:               Show packages installed in the last 7 days, also h, w, m (months) and y")
+	fmt.Println("    date=:2w                        Show packages installed up to 2 weeks ago")
+	fmt.Println("    date=today, date=yesterday      Show packages installed today or yesterday")
+	fmt.Println("    date=2024-05-01T10:00:00Z:      Dates also accept RFC3339 and quoted \"YYYY-MM-DD HH:MM:SS\" timestamps")
 	fmt.Println("    size=10MB:                      Show packages larger than 10MB")
 	fmt.Println("    size=:500KB                     Show packages up to 500KB")
 	fmt.Println("    size=1GB:5GB                    Show packages between 1GB and 5GB")
+	fmt.Println("    size>100MB                      Show packages larger than 100MB, also >=, < and <= for date and size")
+	fmt.Println("    size>=1GiB                      kB, MB, GB and TB are decimal, KiB, MiB, GiB and TiB binary")
+	fmt.Println("    size=1048576                    Sizes in whole bytes (no unit or B) compare exactly, others to 3 significant digits")
+	fmt.Println("    date<=2024-01-01                Show packages installed up to the end of the given date")
 	fmt.Println("    name=firefox              Query packages by names (substring match)")
+	fmt.Println("    name==go                  Exact match, also for arch, license and relation names")
+	fmt.Println("    name=^go$                 Regular expression match, for values starting with ^ or ending with $")
+	fmt.Println("    name~go-*                 Glob match against the whole value")
+	fmt.Println("    name~=firefix             Fuzzy match, allowing typos and abbreviations such as ffmpg")
 	fmt.Println("    reason=explicit           Show only explicitly installed packages")
 	fmt.Println("    reason=dependencies       Show only packages installed as dependencies")
 	fmt.Println("    required-by=vlc           Show packages required by specified packages")
 	fmt.Println("    depends=glibc             Show packages that depend upon specified packages")
+	fmt.Println("    required-by*=vlc          Show everything vlc pulls in, through every level of dependencies and provides")
+	fmt.Println("    depends*2=qt6-base        Show packages that depend on qt6-base within 2 levels, * matches any depth")
+	fmt.Println("    depends!=glibc            Show packages that do not depend upon specified packages, != negates any query")
 	fmt.Println("    provides=awk              Show packages that provide specified libraries, programs, or packages")
 	fmt.Println("    conflicts=fuse            Show packages that conflict with the specified packages.")
 	fmt.Println("    arch=x86_64               Show packages built for the specified architectures. \"any\" is a valid category of architecture.")
+	fmt.Println("    description=browser       Query packages by description, url=... by URL")
+	fmt.Println("    session=vlc               Show packages installed together with the specified packages, or in the specified session numbers")
+
+	fmt.Println("  --search <terms>            Search names and descriptions for all terms, like pacman -Qs, ranked by relevance.")
+	fmt.Println("                               Use --order to order results differently, or -S relevance to show the score")
+	fmt.Println("  --case-sensitive            Match text in queries and searches case-sensitively")
+	fmt.Println("\nData Options:")
+	fmt.Println("  --removed                   Show packages removed according to pacman.log, dated by their removal.")
+	fmt.Println("                               Queries, ordering and output options apply as usual")
+	fmt.Println("  --as-of <date>              Show packages as they were installed at a past moment, reconstructed from pacman.log.")
+	fmt.Println("                               Accepts the same dates as date queries, days, months and years mean their start")
+	fmt.Println("  --no-cache                  Read the package database without loading or saving the cache")
+	fmt.Println("  --rebuild-cache             Ignore the existing cache and save a freshly read one")
+	fmt.Println("  --strict                    Fail instead of skipping malformed package database entries")
+	fmt.Println("  --on-lock <mode>            While pacman holds the database lock: wait, warn (default), or proceed.")
+	fmt.Println("                               Reads that race a transaction are never cached")
+	fmt.Println("  --lock-timeout <duration>   How long --on-lock=wait waits for the lock, e.g. 10s or 2m (default: 30s)")
+	fmt.Println("  --snapshot                  Record a snapshot of the installed packages for later diffs, before queries apply")
 
 	fmt.Println("\nSorting Options:")
 	fmt.Println("  -O, --order <type> Apply sorting to package output.")
@@ -39,14 +85,19 @@ func PrintHelp() {
 	fmt.Println("  --order alphabetical         Sort packages alphabetically")
 	fmt.Println("  --order size:desc            Sort packages by size in descending order")
 	fmt.Println("  --order size:asc             Sort packages by size in ascending order")
+	fmt.Println("  --group-by session           Group packages by install session, the limit applies to sessions")
 
 	fmt.Println("\nOutput Options:")
 	fmt.Println("  --json                      Output results in JSON format")
+	fmt.Println("  --script                    Output suggestions as a pacman -D script for review, never executed (suggest only)")
 	fmt.Println("  --no-headers                Hide headers in table output (useful for scripts)")
 	fmt.Println("  -s, --select <list>         Specify a comma-separated list of fields to display")
 	fmt.Println("  -S, --select-add <list>     Add fields to the default view")
 	fmt.Println("  -A, --select-all            Display all available fields")
 	fmt.Println("  --full-timestamp            Show full timestamps (date + time) for package installations")
+	fmt.Println("  --tz <timezone>             Parse, match and show dates in an IANA timezone such as Europe/Berlin (default: local)")
+	fmt.Println("  --utc                       Parse, match and show dates in UTC")
+	fmt.Println("  --size-units <iec|si>       Show sizes in binary KiB, MiB, GiB (default) or decimal kB, MB, GB units")
 
 	fmt.Println("\nAvailable Fields:")
 	fmt.Println("  date         Installation date of the package")
@@ -61,6 +112,14 @@ func PrintHelp() {
 	fmt.Println("  arch         Architecture the package was built for")
 	fmt.Println("  license      Package software license")
 	fmt.Println("  url          URL of the official site of the software being packaged")
+	fmt.Println("  exclusive-deps  Number of dependencies used only by this explicit package")
+	fmt.Println("  shared-deps     Number of dependencies shared with other explicit packages")
+	fmt.Println("  exclusive-size  Combined size of the dependencies used only by this explicit package")
+	fmt.Println("  shared-size     Combined size of the dependencies shared with other explicit packages")
+	fmt.Println("  first-installed  Date the package was first installed, from pacman.log")
+	fmt.Println("  last-upgrade     Date the package was last upgraded, from pacman.log")
+	fmt.Println("  upgrade-count    Number of times the package was upgraded, from pacman.log")
+	fmt.Println("  session          Install session, packages installed by the same pacman transaction or within a minute of each other")
 
 	fmt.Println("\nExamples:")
 	fmt.Println("  yaylog -l 10                      # Show the last 10 installed packages")
@@ -72,6 +131,17 @@ func PrintHelp() {
 	fmt.Println("  yaylog --json                     # Output package data in JSON format")
 	fmt.Println("  yaylog -w name=sqlite --json      # Output details for SQLite in JSON")
 	fmt.Println("  yaylog --no-headers -s name,size  # Show package names and sizes without headers")
+	fmt.Println("  yaylog -a -w reason=explicit -S exclusive-size -O exclusive-size  # Find the cheapest applications to keep")
+	fmt.Println("  yaylog overlap -w reason=explicit -l 10  # Dependency overlap between the last 10 explicit packages")
+	fmt.Println("  yaylog suggest --script > fix-reasons.sh  # Generate a reviewable install reason correction script")
+	fmt.Println("  yaylog -S upgrade-count -O upgrade-count:desc  # Show the most frequently upgraded packages")
+	fmt.Println("  yaylog --removed -w date=2024-06-01:  # Show packages removed since June 1st, 2024")
+	fmt.Println("  yaylog --as-of 2024-06-01 -a -S version  # Show every package and version installed when June 1st, 2024 began")
+	fmt.Println("  yaylog --group-by session -l 5    # Show the last 5 install sessions")
+	fmt.Println("  yaylog -w session=vlc             # Show packages installed together with VLC")
+	fmt.Println("  yaylog diff                       # Show package changes since the last snapshot")
+	fmt.Println("  yaylog diff 3 last --json         # Show changes between snapshot 3 and the latest one in JSON")
+	fmt.Println("  yaylog trend week                 # Show installed size growth per week")
 
 	fmt.Println("\nFor more details, see the manpage: man yaylog")
 	fmt.Println("Or check the README on the GitHub repo.")
@@ -0,0 +1,565 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"yaylog/internal/consts"
+)
+
+type QueryOp int
+
+const (
+	QueryMatch QueryOp = iota
+	QueryAnd
+	QueryOr
+	QueryNot
+)
+
+type MatchOperator string
+
+const (
+	MatchEqual        MatchOperator = "="
+	MatchNotEqual     MatchOperator = "!="
+	MatchGreater      MatchOperator = ">"
+	MatchGreaterEqual MatchOperator = ">="
+	MatchLess         MatchOperator = "<"
+	MatchLessEqual    MatchOperator = "<="
+	MatchExact        MatchOperator = "=="
+	MatchGlob         MatchOperator = "~"
+	MatchFuzzy        MatchOperator = "~="
+)
+
+// longer operators first, so that >= is not read as >
+var matchOperators = []MatchOperator{
+	MatchExact,
+	MatchNotEqual,
+	MatchGreaterEqual,
+	MatchLessEqual,
+	MatchEqual,
+	MatchGreater,
+	MatchLess,
+	MatchFuzzy,
+	MatchGlob,
+}
+
+// fields whose values are ordered, the only ones comparisons apply to
+var comparableFields = []consts.FieldType{consts.FieldDate, consts.FieldSize}
+
+// fields matched as text, the only ones exact, glob, fuzzy and regex matching apply to
+var textFields = []consts.FieldType{
+	consts.FieldName,
+	consts.FieldArch,
+	consts.FieldLicense,
+	consts.FieldDescription,
+	consts.FieldUrl,
+	consts.FieldDepends,
+	consts.FieldRequiredBy,
+	consts.FieldProvides,
+	consts.FieldConflicts,
+}
+
+// relation fields that can be followed transitively, as in depends*=glibc
+var transitiveFields = []consts.FieldType{consts.FieldDepends, consts.FieldRequiredBy}
+
+// a parsed --where expression, separate --where flags are and'ed together.
+// matches hold a field, operator and value, and/or hold two or more children and not holds one
+type Query struct {
+	Op         QueryOp
+	Field      consts.FieldType
+	Operator   MatchOperator
+	Value      string
+	Transitive bool // follows the relation through every level, or MaxDepth levels
+	MaxDepth   int  // 0 means no limit
+	Children   []*Query
+}
+
+func (operator MatchOperator) IsComparison() bool {
+	switch operator {
+	case MatchGreater, MatchGreaterEqual, MatchLess, MatchLessEqual:
+		return true
+	default:
+		return false
+	}
+}
+
+// = and != on text fields take a regular expression when the value is anchored, as in ^go$
+func (query *Query) IsRegex() bool {
+	return !query.Transitive && (query.Operator == MatchEqual || query.Operator == MatchNotEqual) &&
+		slices.Contains(textFields, query.Field) &&
+		(strings.HasPrefix(query.Value, "^") || strings.HasSuffix(query.Value, "$"))
+}
+
+// a syntax error, Pos is the 1-based position in Input
+type QueryError struct {
+	Input string
+	Pos   int
+	Msg   string
+}
+
+func (queryErr *QueryError) Error() string {
+	return fmt.Sprintf(
+		"Error: invalid query at position %d: %s\n  %s\n  %s^",
+		queryErr.Pos,
+		queryErr.Msg,
+		queryErr.Input,
+		strings.Repeat(" ", queryErr.Pos-1),
+	)
+}
+
+// the field as written, with the * and depth of transitive matches
+func (query *Query) FieldLabel() string {
+	label := consts.FieldNameLookup[query.Field]
+
+	switch {
+	case query.Transitive && query.MaxDepth > 0:
+		return fmt.Sprintf("%s*%d", label, query.MaxDepth)
+	case query.Transitive:
+		return label + "*"
+	default:
+		return label
+	}
+}
+
+// whether any match in the query is on field, safe to call on a nil query
+func (query *Query) HasField(field consts.FieldType) bool {
+	return query.AnyMatch(func(match *Query) bool {
+		return match.Field == field
+	})
+}
+
+// whether predicate holds for any match in the query, safe to call on a nil query
+func (query *Query) AnyMatch(predicate func(match *Query) bool) bool {
+	if query == nil {
+		return false
+	}
+
+	if query.Op == QueryMatch {
+		return predicate(query)
+	}
+
+	for _, child := range query.Children {
+		if child.AnyMatch(predicate) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func newMatchQuery(field consts.FieldType, value string) *Query {
+	return &Query{Op: QueryMatch, Field: field, Operator: MatchEqual, Value: value}
+}
+
+// collapses single children, so that a lone query is never wrapped
+func newJunctionQuery(op QueryOp, children []*Query) *Query {
+	if len(children) == 1 {
+		return children[0]
+	}
+
+	return &Query{Op: op, Children: children}
+}
+
+// and's query with more, either may be nil
+func andQueries(query *Query, more ...*Query) *Query {
+	var children []*Query
+
+	for _, child := range append([]*Query{query}, more...) {
+		switch {
+		case child == nil:
+		case child.Op == QueryAnd:
+			children = append(children, child.Children...)
+		default:
+			children = append(children, child)
+		}
+	}
+
+	if len(children) == 0 {
+		return nil
+	}
+
+	return newJunctionQuery(QueryAnd, children)
+}
+
+type queryTokenType int
+
+const (
+	tokenEnd queryTokenType = iota
+	tokenLParen
+	tokenRParen
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenMatch
+)
+
+type queryToken struct {
+	tokenType   queryTokenType
+	pos         int // 1-based
+	field       string
+	fieldPos    int
+	transitive  bool
+	maxDepth    int
+	depthPos    int
+	operator    MatchOperator
+	operatorPos int
+	value       string
+	valuePos    int
+}
+
+var queryKeywords = map[string]queryTokenType{
+	"and": tokenAnd,
+	"or":  tokenOr,
+	"not": tokenNot,
+}
+
+func isFieldChar(char byte) bool {
+	return isAlphaNumChar(char) || char == '_' || char == '-'
+}
+
+func isAlphaNumChar(char byte) bool {
+	return (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z') || (char >= '0' && char <= '9')
+}
+
+func isSpaceChar(char byte) bool {
+	return char == ' ' || char == '\t' || char == '\n'
+}
+
+func tokenizeQuery(input string) ([]queryToken, error) {
+	var tokens []queryToken
+	i := 0
+
+	for i < len(input) {
+		char := input[i]
+
+		switch {
+		case isSpaceChar(char):
+			i++
+
+		case char == '(':
+			tokens = append(tokens, queryToken{tokenType: tokenLParen, pos: i + 1})
+			i++
+
+		case char == ')':
+			tokens = append(tokens, queryToken{tokenType: tokenRParen, pos: i + 1})
+			i++
+
+		case isFieldChar(char):
+			token, next, err := readWordToken(input, i)
+			if err != nil {
+				return nil, err
+			}
+
+			tokens = append(tokens, token)
+			i = next
+
+		default:
+			return nil, &QueryError{input, i + 1, fmt.Sprintf("unexpected %q", char)}
+		}
+	}
+
+	return append(tokens, queryToken{tokenType: tokenEnd, pos: len(input) + 1}), nil
+}
+
+// a keyword, or a match such as name=vim, size>10MB or depends*2=glibc
+func readWordToken(input string, start int) (queryToken, int, error) {
+	end := start
+	for end < len(input) && isFieldChar(input[end]) {
+		end++
+	}
+
+	word := input[start:end]
+
+	transitive, maxDepth, depthEnd, err := readTransitiveMarker(input, end)
+	if err != nil {
+		return queryToken{}, 0, err
+	}
+
+	depthPos := end + 1
+	end = depthEnd
+	operator := readMatchOperator(input[end:])
+
+	if operator == "" && transitive {
+		return queryToken{}, 0, &QueryError{input, end + 1, fmt.Sprintf("expected '=' or '!=' after %q", input[start:end])}
+	}
+
+	if operator == "" {
+		if tokenType, isKeyword := queryKeywords[strings.ToLower(word)]; isKeyword {
+			return queryToken{tokenType: tokenType, pos: start + 1}, end, nil
+		}
+
+		return queryToken{}, 0, &QueryError{input, end + 1, fmt.Sprintf("expected an operator such as '=' after %q", word)}
+	}
+
+	valueStart := end + len(operator)
+	value, next, err := readQueryValue(input, valueStart)
+	if err != nil {
+		return queryToken{}, 0, err
+	}
+
+	return queryToken{
+		tokenType:   tokenMatch,
+		pos:         start + 1,
+		field:       word,
+		fieldPos:    start + 1,
+		transitive:  transitive,
+		maxDepth:    maxDepth,
+		depthPos:    depthPos,
+		operator:    operator,
+		operatorPos: end + 1,
+		value:       value,
+		valuePos:    valueStart + 1,
+	}, next, nil
+}
+
+// an optional * after the field, followed by an optional depth
+func readTransitiveMarker(input string, start int) (bool, int, int, error) {
+	if start >= len(input) || input[start] != '*' {
+		return false, 0, start, nil
+	}
+
+	end := start + 1
+	for end < len(input) && input[end] >= '0' && input[end] <= '9' {
+		end++
+	}
+
+	if end == start+1 {
+		return true, 0, end, nil
+	}
+
+	maxDepth, err := strconv.Atoi(input[start+1 : end])
+	if err != nil || maxDepth < 1 {
+		return false, 0, 0, &QueryError{input, start + 2, "the depth of a transitive match must be a positive number"}
+	}
+
+	return true, maxDepth, end, nil
+}
+
+func readMatchOperator(input string) MatchOperator {
+	for _, operator := range matchOperators {
+		if strings.HasPrefix(input, string(operator)) {
+			return operator
+		}
+	}
+
+	return ""
+}
+
+// values end at whitespace or a closing parenthesis, unless they are quoted
+func readQueryValue(input string, start int) (string, int, error) {
+	if start < len(input) && (input[start] == '"' || input[start] == '\'') {
+		quote := input[start]
+		end := strings.IndexByte(input[start+1:], quote)
+		if end < 0 {
+			return "", 0, &QueryError{input, start + 1, "unterminated quote"}
+		}
+
+		value := input[start+1 : start+1+end]
+		if value == "" {
+			return "", 0, &QueryError{input, start + 1, "missing value"}
+		}
+
+		return value, start + end + 2, nil
+	}
+
+	end := start
+	for end < len(input) && !isSpaceChar(input[end]) && input[end] != ')' {
+		end++
+	}
+
+	if end == start {
+		return "", 0, &QueryError{input, start + 1, "missing value"}
+	}
+
+	return input[start:end], end, nil
+}
+
+type queryParser struct {
+	input  string
+	tokens []queryToken
+	next   int
+}
+
+// or binds looser than and, which binds looser than not
+func parseQuery(input string) (*Query, error) {
+	tokens, err := tokenizeQuery(input)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := &queryParser{input: input, tokens: tokens}
+	if parser.peek().tokenType == tokenEnd {
+		return nil, &QueryError{input, 1, "empty query"}
+	}
+
+	query, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if token := parser.peek(); token.tokenType != tokenEnd {
+		return nil, parser.errorAt(token, "expected 'and', 'or' or the end of the query")
+	}
+
+	return query, nil
+}
+
+func (parser *queryParser) peek() queryToken {
+	return parser.tokens[parser.next]
+}
+
+func (parser *queryParser) advance() queryToken {
+	token := parser.tokens[parser.next]
+	if token.tokenType != tokenEnd {
+		parser.next++
+	}
+
+	return token
+}
+
+func (parser *queryParser) errorAt(token queryToken, msg string) error {
+	return &QueryError{parser.input, token.pos, msg}
+}
+
+func (parser *queryParser) parseOr() (*Query, error) {
+	return parser.parseJunction(QueryOr, tokenOr, parser.parseAnd)
+}
+
+func (parser *queryParser) parseAnd() (*Query, error) {
+	return parser.parseJunction(QueryAnd, tokenAnd, parser.parseUnary)
+}
+
+func (parser *queryParser) parseJunction(
+	op QueryOp,
+	separator queryTokenType,
+	parseOperand func() (*Query, error),
+) (*Query, error) {
+	operand, err := parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	operands := []*Query{operand}
+
+	for parser.peek().tokenType == separator {
+		parser.advance()
+
+		operand, err = parseOperand()
+		if err != nil {
+			return nil, err
+		}
+
+		operands = append(operands, operand)
+	}
+
+	return newJunctionQuery(op, operands), nil
+}
+
+func (parser *queryParser) parseUnary() (*Query, error) {
+	token := parser.advance()
+
+	switch token.tokenType {
+	case tokenNot:
+		operand, err := parser.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		return &Query{Op: QueryNot, Children: []*Query{operand}}, nil
+
+	case tokenLParen:
+		query, err := parser.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if closing := parser.advance(); closing.tokenType != tokenRParen {
+			return nil, parser.errorAt(closing, "expected ')'")
+		}
+
+		return query, nil
+
+	case tokenMatch:
+		return parser.parseMatch(token)
+
+	case tokenEnd:
+		return nil, parser.errorAt(token, "unexpected end of query, expected field=value")
+
+	default:
+		return nil, parser.errorAt(token, "expected field=value, 'not' or '('")
+	}
+}
+
+func (parser *queryParser) parseMatch(token queryToken) (*Query, error) {
+	fieldType, exists := consts.FieldTypeLookup[strings.ToLower(token.field)]
+	if !exists {
+		return nil, &QueryError{parser.input, token.fieldPos, fmt.Sprintf("unknown filter field: %s", token.field)}
+	}
+
+	if token.transitive && !slices.Contains(transitiveFields, fieldType) {
+		return nil, &QueryError{
+			parser.input,
+			token.depthPos,
+			fmt.Sprintf("only depends and required-by can be matched transitively, not %s", consts.FieldNameLookup[fieldType]),
+		}
+	}
+
+	if token.transitive && token.operator != MatchEqual && token.operator != MatchNotEqual {
+		return nil, &QueryError{
+			parser.input,
+			token.operatorPos,
+			fmt.Sprintf("transitive matches only take = and !=, not %s", token.operator),
+		}
+	}
+
+	if token.operator.IsComparison() && !slices.Contains(comparableFields, fieldType) {
+		return nil, &QueryError{
+			parser.input,
+			token.operatorPos,
+			fmt.Sprintf("operator %s only applies to date and size, not %s", token.operator, consts.FieldNameLookup[fieldType]),
+		}
+	}
+
+	if (token.operator == MatchExact || token.operator == MatchGlob || token.operator == MatchFuzzy) &&
+		!slices.Contains(textFields, fieldType) {
+		return nil, &QueryError{
+			parser.input,
+			token.operatorPos,
+			fmt.Sprintf("operator %s does not apply to %s", token.operator, consts.FieldNameLookup[fieldType]),
+		}
+	}
+
+	query := &Query{
+		Op:         QueryMatch,
+		Field:      fieldType,
+		Operator:   token.operator,
+		Value:      token.value,
+		Transitive: token.transitive,
+		MaxDepth:   token.maxDepth,
+	}
+
+	if query.IsRegex() {
+		if _, err := regexp.Compile(query.Value); err != nil {
+			return nil, &QueryError{parser.input, token.valuePos, fmt.Sprintf("invalid regular expression: %v", err)}
+		}
+	}
+
+	if query.Operator == MatchGlob {
+		if _, err := path.Match(query.Value, ""); err != nil {
+			return nil, &QueryError{parser.input, token.valuePos, fmt.Sprintf("invalid glob pattern: %v", err)}
+		}
+	}
+
+	if fieldType == consts.FieldReason && token.value != ReasonExplicit && token.value != ReasonDependency {
+		return nil, &QueryError{
+			parser.input,
+			token.valuePos,
+			fmt.Sprintf("invalid reason %q, allowed values are 'explicit' or 'dependency'", token.value),
+		}
+	}
+
+	return query, nil
+}
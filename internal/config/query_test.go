@@ -0,0 +1,107 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// renders a query with explicit parentheses, so that precedence shows up in the expected strings
+func formatQuery(query *Query) string {
+	switch query.Op {
+	case QueryNot:
+		return "not " + formatQuery(query.Children[0])
+	case QueryAnd, QueryOr:
+		separator := " or "
+		if query.Op == QueryAnd {
+			separator = " and "
+		}
+
+		parts := make([]string, len(query.Children))
+		for i, child := range query.Children {
+			parts[i] = formatQuery(child)
+		}
+
+		return "(" + strings.Join(parts, separator) + ")"
+	default:
+		return fmt.Sprintf("%s%s%s", query.FieldLabel(), query.Operator, query.Value)
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected string
+	}{
+		{"name=vim", "name=vim"},
+		{"name=python or name=perl", "(name=python or name=perl)"},
+		{"not reason=explicit", "not reason=explicit"},
+		{"name=a or name=b and not arch=any", "(name=a or (name=b and not arch=any))"},
+		{"(name=a or name=b) and arch=any", "((name=a or name=b) and arch=any)"},
+		{"NOT (name=a OR name=b)", "not (name=a or name=b)"},
+		{"name='two words' and size=1MB:", "(name=two words and size=1MB:)"},
+		{"name=vim,emacs", "name=vim,emacs"},
+		{"size>100MB and size<=1GB", "(size>100MB and size<=1GB)"},
+		{"date>=2024-01-01 or depends!=glibc", "(date>=2024-01-01 or depends!=glibc)"},
+		{"size<1KB", "size<1KB"},
+		{"name==go or name~go-*", "(name==go or name~go-*)"},
+		{"name~=firefix", "name~=firefix"},
+		{"url~*github.com* and description=browser", "(url~*github.com* and description=browser)"},
+		{"required-by*=vlc and depends*2!=qt6-base", "(required-by*=vlc and depends*2!=qt6-base)"},
+		{"name='^(go|rust)$'", "name=^(go|rust)$"},
+	}
+
+	for _, c := range cases {
+		query, err := parseQuery(c.input)
+		if err != nil {
+			t.Errorf("parseQuery(%q): unexpected error: %v", c.input, err)
+			continue
+		}
+
+		if result := formatQuery(query); result != c.expected {
+			t.Errorf("parseQuery(%q): expected %s, got %s", c.input, c.expected, result)
+		}
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	cases := []struct {
+		input string
+		pos   int
+	}{
+		{"", 1},
+		{"name=", 6},
+		{"nam=vim", 1},
+		{"name=a or", 10},
+		{"(name=a", 8},
+		{"name=a name=b", 8},
+		{"name=a )", 8},
+		{"name=\"vim", 6},
+		{"reason=maybe", 8},
+		{"vim", 4},
+		{"name>vim", 5},
+		{"size>=", 7},
+		{"size~1MB", 5},
+		{"reason~=explicit", 7},
+		{"name*=vim", 5},
+		{"depends*0=glibc", 9},
+		{"depends*>1", 9},
+		{"depends*", 9},
+		{"name=^(go", 6},
+	}
+
+	for _, c := range cases {
+		_, err := parseQuery(c.input)
+
+		var queryErr *QueryError
+		if !errors.As(err, &queryErr) {
+			t.Errorf("parseQuery(%q): expected a syntax error, got %v", c.input, err)
+			continue
+		}
+
+		if queryErr.Pos != c.pos {
+			t.Errorf("parseQuery(%q): expected error at position %d, got %d (%s)", c.input, c.pos, queryErr.Pos, queryErr.Msg)
+		}
+	}
+}
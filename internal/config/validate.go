@@ -2,6 +2,9 @@ package config
 
 import (
 	"fmt"
+	"slices"
+	"time"
+	"yaylog/internal/consts"
 )
 
 func validateFlagCombinations(
@@ -21,3 +24,151 @@ func validateFlagCombinations(
 
 	return nil
 }
+
+func validateCommandFlags(
+	command string,
+	outputJson bool,
+	outputScript bool,
+	showRemoved bool,
+	recordSnapshot bool,
+	asOf int64,
+) error {
+	if outputScript && command != CommandSuggest {
+		return fmt.Errorf("Error: --script can only be used with the %s command", CommandSuggest)
+	}
+
+	if outputScript && outputJson {
+		return fmt.Errorf("Error: cannot use --script and --json at the same time")
+	}
+
+	if showRemoved && command != "" {
+		return fmt.Errorf("Error: --removed cannot be used with the %s command", command)
+	}
+
+	if asOf != 0 && showRemoved {
+		return fmt.Errorf("Error: cannot use --as-of and --removed at the same time")
+	}
+
+	// file lists only exist for packages that are currently installed
+	if asOf != 0 && command == CommandSuggest {
+		return fmt.Errorf("Error: --as-of cannot be used with the %s command", command)
+	}
+
+	// snapshots always record the installed package set as it is now
+	if recordSnapshot && (showRemoved || asOf != 0) {
+		return fmt.Errorf("Error: --snapshot cannot be used with --removed or --as-of")
+	}
+
+	if asOf != 0 && (isSnapshotCommand(command) || command == CommandDoctor) {
+		return fmt.Errorf("Error: --as-of cannot be used with the %s command", command)
+	}
+
+	// recording before diffing would always compare the current packages with themselves,
+	// and trends and database checks never record anything
+	if recordSnapshot && (command == CommandDiff || command == CommandTrend || command == CommandDoctor) {
+		return fmt.Errorf("Error: --snapshot cannot be used with the %s command", command)
+	}
+
+	return nil
+}
+
+// snapshots, diffs and database checks cover the whole package set
+func validateCommandQueries(command string, filterQuery *Query) error {
+	if filterQuery != nil && (isSnapshotCommand(command) || command == CommandDoctor) {
+		return fmt.Errorf("Error: queries cannot be used with the %s command", command)
+	}
+
+	return nil
+}
+
+// relevance only exists for search results
+func validateSearch(command string, searchTerms []string, sortOption SortOption, fields []consts.FieldType) error {
+	if len(searchTerms) == 0 {
+		if sortOption.Field == consts.FieldRelevance || slices.Contains(fields, consts.FieldRelevance) {
+			return fmt.Errorf("Error: relevance is only available with --search")
+		}
+
+		return nil
+	}
+
+	if command != "" {
+		return fmt.Errorf("Error: --search cannot be used with the %s command", command)
+	}
+
+	return nil
+}
+
+func validateCacheFlags(command string, noCache bool, rebuildCache bool) error {
+	if noCache && rebuildCache {
+		return fmt.Errorf("Error: cannot use --no-cache and --rebuild-cache at the same time")
+	}
+
+	if (noCache || rebuildCache) && (command == CommandCache || command == CommandDoctor) {
+		return fmt.Errorf("Error: --no-cache and --rebuild-cache cannot be used with the %s command", command)
+	}
+
+	return nil
+}
+
+// only runs that read the package database can find malformed entries
+func validateStrict(command string, strict bool, showRemoved bool) error {
+	if !strict {
+		return nil
+	}
+
+	if showRemoved {
+		return fmt.Errorf("Error: --strict cannot be used with --removed")
+	}
+
+	if command == CommandTrend || command == CommandCache {
+		return fmt.Errorf("Error: --strict cannot be used with the %s command", command)
+	}
+
+	return nil
+}
+
+func validateOnLock(onLock string, lockTimeout time.Duration) error {
+	switch onLock {
+	case OnLockWait, OnLockWarn, OnLockProceed:
+	default:
+		return fmt.Errorf("Error: invalid --on-lock value: %s. Expected one of: %s, %s, %s", onLock, OnLockWait, OnLockWarn, OnLockProceed)
+	}
+
+	if lockTimeout <= 0 {
+		return fmt.Errorf("Error: --lock-timeout must be positive")
+	}
+
+	return nil
+}
+
+func validateSizeUnits(sizeUnits string) error {
+	if sizeUnits != consts.SizeUnitsIec && sizeUnits != consts.SizeUnitsSi {
+		return fmt.Errorf("Error: invalid --size-units value: %s. Expected %s or %s", sizeUnits, consts.SizeUnitsIec, consts.SizeUnitsSi)
+	}
+
+	return nil
+}
+
+func isSnapshotCommand(command string) bool {
+	return command == CommandSnapshot || command == CommandDiff || command == CommandTrend
+}
+
+func validateGroupBy(groupBy string, command string, showRemoved bool) error {
+	if groupBy == "" {
+		return nil
+	}
+
+	if groupBy != GroupBySession {
+		return fmt.Errorf("Error: invalid --group-by value: %s. Only '%s' is supported", groupBy, GroupBySession)
+	}
+
+	if command != "" {
+		return fmt.Errorf("Error: --group-by cannot be used with the %s command", command)
+	}
+
+	if showRemoved {
+		return fmt.Errorf("Error: cannot use --group-by and --removed at the same time")
+	}
+
+	return nil
+}
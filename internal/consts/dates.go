@@ -5,3 +5,10 @@ const (
 	DateTimeFormat       = "2006-01-02 15:04:05"
 	DefaultTerminalWidth = 80
 )
+
+// periods the trend command groups snapshots by
+const (
+	TrendDay   = "day"
+	TrendWeek  = "week"
+	TrendMonth = "month"
+)
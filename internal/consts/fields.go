@@ -5,6 +5,7 @@ type FieldType int
 // ordered by filter efficiency
 const (
 	FieldReason FieldType = iota
+	FieldSession
 	FieldArch
 	FieldLicense
 	FieldName
@@ -17,22 +18,39 @@ const (
 	FieldRequiredBy
 	FieldProvides
 	FieldConflicts
+	FieldExclusiveDeps
+	FieldSharedDeps
+	FieldExclusiveSize
+	FieldSharedSize
+	FieldFirstInstalled
+	FieldLastUpgrade
+	FieldUpgradeCount
+	FieldRelevance
 )
 
 const (
-	date        = "date"
-	name        = "name"
-	reason      = "reason"
-	size        = "size"
-	version     = "version"
-	description = "description"
-	depends     = "depends"
-	requiredBy  = "required-by"
-	provides    = "provides"
-	conflicts   = "conflicts"
-	arch        = "arch"
-	license     = "license"
-	url         = "url"
+	date           = "date"
+	name           = "name"
+	reason         = "reason"
+	size           = "size"
+	version        = "version"
+	description    = "description"
+	depends        = "depends"
+	requiredBy     = "required-by"
+	provides       = "provides"
+	conflicts      = "conflicts"
+	arch           = "arch"
+	license        = "license"
+	url            = "url"
+	exclusiveDeps  = "exclusive-deps"
+	sharedDeps     = "shared-deps"
+	exclusiveSize  = "exclusive-size"
+	sharedSize     = "shared-size"
+	firstInstalled = "first-installed"
+	lastUpgrade    = "last-upgrade"
+	upgradeCount   = "upgrade-count"
+	session        = "session"
+	relevance      = "relevance"
 )
 
 var FieldTypeLookup = map[string]FieldType{
@@ -47,34 +65,53 @@ var FieldTypeLookup = map[string]FieldType{
 
 	"alphabetical": FieldName, // legacy flag, to be deprecated
 
-	date:        FieldDate,
-	name:        FieldName,
-	reason:      FieldReason,
-	arch:        FieldArch,
-	license:     FieldLicense,
-	url:         FieldUrl,
-	description: FieldDescription,
-	size:        FieldSize,
-	version:     FieldVersion,
-	depends:     FieldDepends,
-	requiredBy:  FieldRequiredBy,
-	provides:    FieldProvides,
-	conflicts:   FieldConflicts,
+	date:           FieldDate,
+	name:           FieldName,
+	reason:         FieldReason,
+	arch:           FieldArch,
+	license:        FieldLicense,
+	url:            FieldUrl,
+	description:    FieldDescription,
+	size:           FieldSize,
+	version:        FieldVersion,
+	depends:        FieldDepends,
+	requiredBy:     FieldRequiredBy,
+	provides:       FieldProvides,
+	conflicts:      FieldConflicts,
+	exclusiveDeps:  FieldExclusiveDeps,
+	sharedDeps:     FieldSharedDeps,
+	exclusiveSize:  FieldExclusiveSize,
+	sharedSize:     FieldSharedSize,
+	firstInstalled: FieldFirstInstalled,
+	lastUpgrade:    FieldLastUpgrade,
+	upgradeCount:   FieldUpgradeCount,
+	session:        FieldSession,
+	relevance:      FieldRelevance,
 }
 
 var FieldNameLookup = map[FieldType]string{
-	FieldDate:       date,
-	FieldName:       name,
-	FieldSize:       size,
-	FieldReason:     reason,
-	FieldVersion:    version,
-	FieldDepends:    depends,
-	FieldRequiredBy: requiredBy,
-	FieldProvides:   provides,
-	FieldConflicts:  conflicts,
-	FieldArch:       arch,
-	FieldLicense:    license,
-	FieldUrl:        url,
+	FieldDate:           date,
+	FieldName:           name,
+	FieldSize:           size,
+	FieldReason:         reason,
+	FieldVersion:        version,
+	FieldDepends:        depends,
+	FieldRequiredBy:     requiredBy,
+	FieldProvides:       provides,
+	FieldConflicts:      conflicts,
+	FieldArch:           arch,
+	FieldLicense:        license,
+	FieldUrl:            url,
+	FieldDescription:    description,
+	FieldExclusiveDeps:  exclusiveDeps,
+	FieldSharedDeps:     sharedDeps,
+	FieldExclusiveSize:  exclusiveSize,
+	FieldSharedSize:     sharedSize,
+	FieldFirstInstalled: firstInstalled,
+	FieldLastUpgrade:    lastUpgrade,
+	FieldUpgradeCount:   upgradeCount,
+	FieldSession:        session,
+	FieldRelevance:      relevance,
 }
 
 var (
@@ -84,6 +121,11 @@ var (
 		FieldReason,
 		FieldSize,
 	}
+	RemovedDefaultFields = []FieldType{
+		FieldDate,
+		FieldName,
+		FieldVersion,
+	}
 	ValidFields = []FieldType{
 		FieldDate,
 		FieldName,
@@ -98,5 +140,13 @@ var (
 		FieldLicense,
 		FieldUrl,
 		FieldDescription,
+		FieldExclusiveDeps,
+		FieldSharedDeps,
+		FieldExclusiveSize,
+		FieldSharedSize,
+		FieldFirstInstalled,
+		FieldLastUpgrade,
+		FieldUpgradeCount,
+		FieldSession,
 	}
 )
@@ -1,7 +1,23 @@
 package consts
 
+// binary (IEC) units
 const (
-	KB = 1024
-	MB = KB * KB
-	GB = MB * MB
+	KiB = 1024
+	MiB = KiB * 1024
+	GiB = MiB * 1024
+	TiB = GiB * 1024
+)
+
+// decimal (SI) units
+const (
+	KB = 1000
+	MB = KB * 1000
+	GB = MB * 1000
+	TB = GB * 1000
+)
+
+// how sizes are displayed, see --size-units
+const (
+	SizeUnitsIec = "iec"
+	SizeUnitsSi  = "si"
 )
@@ -5,6 +5,7 @@ import (
 	"os"
 	"strings"
 	"sync"
+	"time"
 	"yaylog/internal/consts"
 	"yaylog/internal/pkgdata"
 
@@ -56,12 +57,13 @@ func RenderTable(
 	fields []consts.FieldType,
 	showFullTimestamp bool,
 	hasNoHeaders bool,
+	location *time.Location,
 ) {
-	manager.renderTable(pkgPtrs, fields, showFullTimestamp, hasNoHeaders)
+	manager.renderTable(pkgPtrs, fields, showFullTimestamp, hasNoHeaders, location)
 }
 
-func RenderJson(pkgPtrs []*pkgdata.PkgInfo, fields []consts.FieldType) {
-	manager.renderJson(pkgPtrs, fields)
+func RenderJson(pkgPtrs []*pkgdata.PkgInfo, fields []consts.FieldType, location *time.Location) {
+	manager.renderJson(pkgPtrs, fields, location)
 }
 
 func (o *OutputManager) write(msg string) {
@@ -0,0 +1,108 @@
+package display
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"yaylog/internal/consts"
+	"yaylog/internal/pipeline/meta"
+	"yaylog/internal/pkgdata"
+)
+
+const (
+	CacheCurrent  = "current"
+	CacheStale    = "stale"
+	CacheMissing  = "missing"
+	CacheOutdated = "outdated"
+	CacheCorrupt  = "corrupt"
+)
+
+var cacheStatusNotes = map[string]string{
+	CacheStale:    "changed packages will be reparsed on the next run",
+	CacheOutdated: "written by another yaylog version, it will be rebuilt on the next run",
+	CacheCorrupt:  "it will be rebuilt on the next run",
+}
+
+type cacheInfoJson struct {
+	Path      string   `json:"path"`
+	Status    string   `json:"status"`
+	Version   int32    `json:"version,omitempty"`
+	Size      int64    `json:"size,omitempty"`
+	Packages  int      `json:"packages,omitempty"`
+	DbModTime int64    `json:"dbModTime,omitempty"`
+	IsStale   bool     `json:"stale"`
+	Derived   []string `json:"derived"`
+}
+
+// status is one of the Cache* statuses
+func RenderCacheInfo(info pkgdata.CacheInfo, status string, outputJson bool, location *time.Location) {
+	if outputJson {
+		manager.writeJson(cacheInfoJson{
+			Path:      info.Path,
+			Status:    status,
+			Version:   info.Version,
+			Size:      info.Size,
+			Packages:  info.PkgCount,
+			DbModTime: info.DbModTime,
+			IsStale:   info.IsStale,
+			Derived:   describeDerivedData(info.Derived),
+		})
+
+		return
+	}
+
+	manager.renderCacheInfoTable(info, status, location)
+}
+
+func (o *OutputManager) renderCacheInfoTable(info pkgdata.CacheInfo, status string, location *time.Location) {
+	o.clearProgress()
+
+	var buffer bytes.Buffer
+	w := tabwriter.NewWriter(&buffer, 0, 8, 2, ' ', 0)
+
+	fmt.Fprintf(w, "Location:\t%s\n", info.Path)
+	if note, hasNote := cacheStatusNotes[status]; hasNote {
+		fmt.Fprintf(w, "Status:\t%s, %s\n", status, note)
+	} else {
+		fmt.Fprintf(w, "Status:\t%s\n", status)
+	}
+
+	if info.Version != 0 {
+		fmt.Fprintf(w, "Version:\t%d\n", info.Version)
+		fmt.Fprintf(w, "Size:\t%s\n", formatSize(info.Size))
+		fmt.Fprintf(w, "Packages:\t%d\n", info.PkgCount)
+		fmt.Fprintf(w, "Derived data:\t%s\n", formatDerivedData(info.Derived))
+		fmt.Fprintf(w, "Built against:\t%s (package database mod time)\n", time.Unix(info.DbModTime, 0).In(location).Format(consts.DateTimeFormat))
+	}
+
+	w.Flush()
+	o.write(buffer.String())
+}
+
+var derivedDataNames = map[meta.DerivedData]string{
+	meta.DerivedReverseDeps: "required-by",
+}
+
+func describeDerivedData(derived meta.DerivedData) []string {
+	names := []string{}
+	for data, name := range derivedDataNames {
+		if derived.Has(data) {
+			names = append(names, name)
+		}
+	}
+
+	slices.Sort(names)
+	return names
+}
+
+func formatDerivedData(derived meta.DerivedData) string {
+	names := describeDerivedData(derived)
+	if len(names) == 0 {
+		return "none, computed when first needed"
+	}
+
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,88 @@
+package display
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"yaylog/internal/pkgdata"
+)
+
+type diagnosticJson struct {
+	Path   string `json:"path"`
+	Line   int    `json:"line,omitempty"`
+	Field  string `json:"field,omitempty"`
+	Reason string `json:"reason"`
+}
+
+type doctorReportJson struct {
+	Checked     int              `json:"checked"`
+	Diagnostics []diagnosticJson `json:"diagnostics"`
+}
+
+// checked is the number of database entries the diagnostics were collected from
+func RenderDiagnostics(diagnostics []pkgdata.Diagnostic, checked int, outputJson bool, hasNoHeaders bool) {
+	if outputJson {
+		manager.renderDiagnosticsJson(diagnostics, checked)
+		return
+	}
+
+	manager.renderDiagnosticsTable(diagnostics, checked, hasNoHeaders)
+}
+
+func (o *OutputManager) renderDiagnosticsTable(diagnostics []pkgdata.Diagnostic, checked int, hasNoHeaders bool) {
+	o.clearProgress()
+
+	if len(diagnostics) == 0 {
+		o.writeLine(fmt.Sprintf("No malformed entries in %d package database entries.", checked))
+		return
+	}
+
+	var buffer bytes.Buffer
+	w := tabwriter.NewWriter(&buffer, 0, 8, 2, ' ', 0)
+
+	if !hasNoHeaders {
+		fmt.Fprintln(w, "PATH\tLINE\tFIELD\tREASON")
+	}
+
+	for _, diagnostic := range diagnostics {
+		fmt.Fprintln(w, strings.Join([]string{
+			diagnostic.Path,
+			formatOptionalValue(formatDiagnosticLine(diagnostic.Line)),
+			formatOptionalValue(diagnostic.Field),
+			diagnostic.Reason,
+		}, "\t"))
+	}
+
+	w.Flush()
+
+	if !hasNoHeaders {
+		fmt.Fprintf(&buffer, "\n%d of %d package database entries are malformed, they are left out of listings.\n", len(diagnostics), checked)
+	}
+
+	o.write(buffer.String())
+}
+
+func formatDiagnosticLine(line int) string {
+	if line == 0 {
+		return ""
+	}
+
+	return strconv.Itoa(line)
+}
+
+func (o *OutputManager) renderDiagnosticsJson(diagnostics []pkgdata.Diagnostic, checked int) {
+	diagnosticOutputs := make([]diagnosticJson, len(diagnostics))
+
+	for i, diagnostic := range diagnostics {
+		diagnosticOutputs[i] = diagnosticJson{
+			Path:   diagnostic.Path,
+			Line:   diagnostic.Line,
+			Field:  diagnostic.Field,
+			Reason: diagnostic.Reason,
+		}
+	}
+
+	o.writeJson(doctorReportJson{Checked: checked, Diagnostics: diagnosticOutputs})
+}
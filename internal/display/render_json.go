@@ -4,12 +4,14 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"time"
 	"yaylog/internal/consts"
 	"yaylog/internal/pkgdata"
 )
 
 type PkgInfoJson struct {
 	Timestamp   int64    `json:"timestamp,omitempty"`
+	Date        string   `json:"date,omitempty"` // ISO-8601, in the --tz timezone
 	Size        int64    `json:"size,omitempty"`
 	Name        string   `json:"name,omitempty"`
 	Reason      string   `json:"reason,omitempty"`
@@ -22,18 +24,35 @@ type PkgInfoJson struct {
 	RequiredBy  []string `json:"requiredBy,omitempty"`
 	Provides    []string `json:"provides,omitempty"`
 	Conflicts   []string `json:"conflicts,omitempty"`
+
+	ExclusiveDeps int   `json:"exclusiveDeps,omitempty"`
+	SharedDeps    int   `json:"sharedDeps,omitempty"`
+	ExclusiveSize int64 `json:"exclusiveSize,omitempty"`
+	SharedSize    int64 `json:"sharedSize,omitempty"`
+
+	FirstInstalled     int64  `json:"firstInstalled,omitempty"`
+	FirstInstalledDate string `json:"firstInstalledDate,omitempty"` // ISO-8601, like date
+	LastUpgrade        int64  `json:"lastUpgrade,omitempty"`
+	LastUpgradeDate    string `json:"lastUpgradeDate,omitempty"`
+	UpgradeCount       int    `json:"upgradeCount,omitempty"`
+	Session            int    `json:"session,omitempty"`
+	Relevance          int    `json:"relevance,omitempty"`
 }
 
-func (o *OutputManager) renderJson(pkgPtrs []*pkgdata.PkgInfo, fields []consts.FieldType) {
+func (o *OutputManager) renderJson(pkgPtrs []*pkgdata.PkgInfo, fields []consts.FieldType, location *time.Location) {
 	uniqueFields := getUniqueFields(fields)
-	filteredPkgPtrs := selectJsonFields(pkgPtrs, uniqueFields)
+	filteredPkgPtrs := selectJsonFields(pkgPtrs, uniqueFields, location)
+
+	o.writeJson(filteredPkgPtrs)
+}
 
+func (o *OutputManager) writeJson(value any) {
 	var buffer bytes.Buffer
 	encoder := json.NewEncoder(&buffer)
 	encoder.SetEscapeHTML(false) // disable escaping of characters like `<`, `>`, perhaps this should be a user defined option
 	encoder.SetIndent("", "  ")
 
-	if err := encoder.Encode(filteredPkgPtrs); err != nil {
+	if err := encoder.Encode(value); err != nil {
 		o.writeLine(fmt.Sprintf("Error genereating JSON output: %v", err))
 	}
 
@@ -57,22 +76,24 @@ func getUniqueFields(fields []consts.FieldType) []consts.FieldType {
 func selectJsonFields(
 	pkgPtrs []*pkgdata.PkgInfo,
 	fields []consts.FieldType,
+	location *time.Location,
 ) []*PkgInfoJson {
 	filteredPkgPtrs := make([]*PkgInfoJson, len(pkgPtrs))
 	for i, pkg := range pkgPtrs {
-		filteredPkgPtrs[i] = getJsonValues(pkg, fields)
+		filteredPkgPtrs[i] = getJsonValues(pkg, fields, location)
 	}
 
 	return filteredPkgPtrs
 }
 
-func getJsonValues(pkg *pkgdata.PkgInfo, fields []consts.FieldType) *PkgInfoJson {
+func getJsonValues(pkg *pkgdata.PkgInfo, fields []consts.FieldType, location *time.Location) *PkgInfoJson {
 	filteredPackage := PkgInfoJson{}
 
 	for _, field := range fields {
 		switch field {
 		case consts.FieldDate:
 			filteredPackage.Timestamp = pkg.Timestamp
+			filteredPackage.Date = formatJsonDate(pkg.Timestamp, location)
 		case consts.FieldName:
 			filteredPackage.Name = pkg.Name
 		case consts.FieldReason:
@@ -97,12 +118,41 @@ func getJsonValues(pkg *pkgdata.PkgInfo, fields []consts.FieldType) *PkgInfoJson
 			filteredPackage.Provides = flattenRelations(pkg.Provides)
 		case consts.FieldConflicts:
 			filteredPackage.Conflicts = flattenRelations(pkg.Conflicts)
+		case consts.FieldExclusiveDeps:
+			filteredPackage.ExclusiveDeps = pkg.ExclusiveDeps
+		case consts.FieldSharedDeps:
+			filteredPackage.SharedDeps = pkg.SharedDeps
+		case consts.FieldExclusiveSize:
+			filteredPackage.ExclusiveSize = pkg.ExclusiveSize
+		case consts.FieldSharedSize:
+			filteredPackage.SharedSize = pkg.SharedSize
+		case consts.FieldFirstInstalled:
+			filteredPackage.FirstInstalled = pkg.FirstInstalled
+			filteredPackage.FirstInstalledDate = formatJsonDate(pkg.FirstInstalled, location)
+		case consts.FieldLastUpgrade:
+			filteredPackage.LastUpgrade = pkg.LastUpgrade
+			filteredPackage.LastUpgradeDate = formatJsonDate(pkg.LastUpgrade, location)
+		case consts.FieldUpgradeCount:
+			filteredPackage.UpgradeCount = pkg.UpgradeCount
+		case consts.FieldSession:
+			filteredPackage.Session = pkg.Session
+		case consts.FieldRelevance:
+			filteredPackage.Relevance = pkg.Relevance
 		}
 	}
 
 	return &filteredPackage
 }
 
+// zero means the event is not in the pacman log, and is left out like the timestamp
+func formatJsonDate(timestamp int64, location *time.Location) string {
+	if timestamp == 0 {
+		return ""
+	}
+
+	return time.Unix(timestamp, 0).In(location).Format(time.RFC3339)
+}
+
 func flattenRelations(relations []pkgdata.Relation) []string {
 	relationOutputs := make([]string, 0, len(relations))
 
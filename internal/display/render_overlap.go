@@ -0,0 +1,84 @@
+package display
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"yaylog/internal/pkgdata"
+)
+
+type overlapJson struct {
+	Name         string         `json:"name"`
+	Dependencies int            `json:"dependencies"`
+	Overlap      map[string]int `json:"overlap"`
+}
+
+func RenderOverlapMatrix(matrix pkgdata.OverlapMatrix, outputJson bool, hasNoHeaders bool) {
+	if outputJson {
+		manager.renderOverlapJson(matrix)
+		return
+	}
+
+	manager.renderOverlapTable(matrix, hasNoHeaders)
+}
+
+// columns are numbered to keep the matrix narrow, the number matches the row index
+func (o *OutputManager) renderOverlapTable(matrix pkgdata.OverlapMatrix, hasNoHeaders bool) {
+	o.clearProgress()
+
+	if len(matrix.Names) == 0 {
+		o.writeLine("No explicitly installed packages to compare.")
+		return
+	}
+
+	var buffer bytes.Buffer
+	w := tabwriter.NewWriter(&buffer, 0, 8, 2, ' ', 0)
+
+	if !hasNoHeaders {
+		headers := make([]string, 0, len(matrix.Names)+2)
+		headers = append(headers, "#", "NAME")
+
+		for i := range matrix.Names {
+			headers = append(headers, strconv.Itoa(i+1))
+		}
+
+		fmt.Fprintln(w, strings.Join(headers, "\t"))
+	}
+
+	for i, name := range matrix.Names {
+		row := make([]string, 0, len(matrix.Names)+2)
+		row = append(row, strconv.Itoa(i+1), name)
+
+		for _, count := range matrix.Counts[i] {
+			row = append(row, strconv.Itoa(count))
+		}
+
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+
+	w.Flush()
+	o.write(buffer.String())
+}
+
+func (o *OutputManager) renderOverlapJson(matrix pkgdata.OverlapMatrix) {
+	rows := make([]overlapJson, len(matrix.Names))
+
+	for i, name := range matrix.Names {
+		overlap := make(map[string]int, len(matrix.Names)-1)
+		for j, otherName := range matrix.Names {
+			if i != j {
+				overlap[otherName] = matrix.Counts[i][j]
+			}
+		}
+
+		rows[i] = overlapJson{
+			Name:         name,
+			Dependencies: matrix.Counts[i][i],
+			Overlap:      overlap,
+		}
+	}
+
+	o.writeJson(rows)
+}
@@ -0,0 +1,97 @@
+package display
+
+import (
+	"bytes"
+	"fmt"
+	"text/tabwriter"
+	"time"
+	"yaylog/internal/consts"
+	"yaylog/internal/pkgdata"
+)
+
+type sessionJson struct {
+	Session   int            `json:"session"`
+	Timestamp int64          `json:"timestamp"`
+	Packages  []*PkgInfoJson `json:"packages"`
+}
+
+func RenderSessions(
+	sessions []*pkgdata.Session,
+	fields []consts.FieldType,
+	outputJson bool,
+	showFullTimestamp bool,
+	hasNoHeaders bool,
+	location *time.Location,
+) {
+	if outputJson {
+		manager.renderSessionsJson(sessions, fields, location)
+		return
+	}
+
+	manager.renderSessionsTable(sessions, fields, showFullTimestamp, hasNoHeaders, location)
+}
+
+func (o *OutputManager) renderSessionsTable(
+	sessions []*pkgdata.Session,
+	fields []consts.FieldType,
+	showFullTimestamp bool,
+	hasNoHeaders bool,
+	location *time.Location,
+) {
+	o.clearProgress()
+
+	ctx := newTableContext(showFullTimestamp, location)
+	var buffer bytes.Buffer
+
+	for i, session := range sessions {
+		if i > 0 {
+			buffer.WriteString("\n")
+		}
+
+		pkgCountLabel := "packages"
+		if len(session.Pkgs) == 1 {
+			pkgCountLabel = "package"
+		}
+
+		buffer.WriteString(fmt.Sprintf(
+			"Session %d, %s (%d %s)\n",
+			session.Id,
+			time.Unix(session.Timestamp, 0).In(location).Format(consts.DateTimeFormat),
+			len(session.Pkgs),
+			pkgCountLabel,
+		))
+
+		w := tabwriter.NewWriter(&buffer, 0, 8, 2, ' ', 0)
+
+		if !hasNoHeaders {
+			renderHeaders(w, fields)
+		}
+
+		for _, pkg := range session.Pkgs {
+			renderRows(w, pkg, fields, ctx)
+		}
+
+		w.Flush()
+	}
+
+	o.write(buffer.String())
+}
+
+func (o *OutputManager) renderSessionsJson(
+	sessions []*pkgdata.Session,
+	fields []consts.FieldType,
+	location *time.Location,
+) {
+	uniqueFields := getUniqueFields(fields)
+	sessionOutputs := make([]sessionJson, len(sessions))
+
+	for i, session := range sessions {
+		sessionOutputs[i] = sessionJson{
+			Session:   session.Id,
+			Timestamp: session.Timestamp,
+			Packages:  selectJsonFields(session.Pkgs, uniqueFields, location),
+		}
+	}
+
+	o.writeJson(sessionOutputs)
+}
@@ -0,0 +1,192 @@
+package display
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"yaylog/internal/consts"
+	"yaylog/internal/pkgdata"
+)
+
+type snapshotJson struct {
+	Id        int   `json:"id,omitempty"` // omitted for the current package set
+	Timestamp int64 `json:"timestamp"`
+	Packages  int   `json:"packages"`
+}
+
+type pkgChangeJson struct {
+	Name   string `json:"name"`
+	Change string `json:"change"`
+	Old    string `json:"old,omitempty"`
+	New    string `json:"new,omitempty"`
+}
+
+type snapshotDiffJson struct {
+	From    snapshotJson    `json:"from"`
+	To      snapshotJson    `json:"to"`
+	Changes []pkgChangeJson `json:"changes"`
+}
+
+func RenderSnapshotList(
+	snapshots []*pkgdata.Snapshot,
+	outputJson bool,
+	hasNoHeaders bool,
+	location *time.Location,
+) {
+	if outputJson {
+		manager.renderSnapshotListJson(snapshots)
+		return
+	}
+
+	manager.renderSnapshotListTable(snapshots, hasNoHeaders, location)
+}
+
+func RenderSnapshotDiff(
+	from *pkgdata.Snapshot,
+	to *pkgdata.Snapshot,
+	changes []pkgdata.PkgChange,
+	outputJson bool,
+	hasNoHeaders bool,
+	location *time.Location,
+) {
+	if outputJson {
+		manager.renderSnapshotDiffJson(from, to, changes)
+		return
+	}
+
+	manager.renderSnapshotDiffTable(from, to, changes, hasNoHeaders, location)
+}
+
+func (o *OutputManager) renderSnapshotListTable(
+	snapshots []*pkgdata.Snapshot,
+	hasNoHeaders bool,
+	location *time.Location,
+) {
+	o.clearProgress()
+
+	if len(snapshots) == 0 {
+		o.writeLine("No snapshots recorded yet.")
+		return
+	}
+
+	var buffer bytes.Buffer
+	w := tabwriter.NewWriter(&buffer, 0, 8, 2, ' ', 0)
+
+	if !hasNoHeaders {
+		fmt.Fprintln(w, "ID\tDATE\tPACKAGES")
+	}
+
+	for _, snapshot := range snapshots {
+		fmt.Fprintln(w, strings.Join([]string{
+			strconv.Itoa(snapshot.Id),
+			time.Unix(snapshot.Timestamp, 0).In(location).Format(consts.DateTimeFormat),
+			strconv.Itoa(len(snapshot.Pkgs)),
+		}, "\t"))
+	}
+
+	w.Flush()
+	o.write(buffer.String())
+}
+
+func (o *OutputManager) renderSnapshotListJson(snapshots []*pkgdata.Snapshot) {
+	snapshotOutputs := make([]snapshotJson, len(snapshots))
+	for i, snapshot := range snapshots {
+		snapshotOutputs[i] = toSnapshotJson(snapshot)
+	}
+
+	o.writeJson(snapshotOutputs)
+}
+
+func (o *OutputManager) renderSnapshotDiffTable(
+	from *pkgdata.Snapshot,
+	to *pkgdata.Snapshot,
+	changes []pkgdata.PkgChange,
+	hasNoHeaders bool,
+	location *time.Location,
+) {
+	o.clearProgress()
+
+	var buffer bytes.Buffer
+
+	if !hasNoHeaders {
+		buffer.WriteString(fmt.Sprintf("Changes from %s to %s\n", formatSnapshotLabel(from, location), formatSnapshotLabel(to, location)))
+	}
+
+	if len(changes) == 0 {
+		buffer.WriteString("No changes.\n")
+		o.write(buffer.String())
+		return
+	}
+
+	w := tabwriter.NewWriter(&buffer, 0, 8, 2, ' ', 0)
+
+	if !hasNoHeaders {
+		fmt.Fprintln(w, "CHANGE\tNAME\tOLD\tNEW")
+	}
+
+	for _, change := range changes {
+		fmt.Fprintln(w, strings.Join([]string{
+			string(change.Change),
+			change.Name,
+			formatOptionalValue(change.Old),
+			formatOptionalValue(change.New),
+		}, "\t"))
+	}
+
+	w.Flush()
+	o.write(buffer.String())
+}
+
+func (o *OutputManager) renderSnapshotDiffJson(
+	from *pkgdata.Snapshot,
+	to *pkgdata.Snapshot,
+	changes []pkgdata.PkgChange,
+) {
+	changeOutputs := make([]pkgChangeJson, len(changes))
+	for i, change := range changes {
+		changeOutputs[i] = pkgChangeJson{
+			Name:   change.Name,
+			Change: string(change.Change),
+			Old:    change.Old,
+			New:    change.New,
+		}
+	}
+
+	o.writeJson(snapshotDiffJson{
+		From:    toSnapshotJson(from),
+		To:      toSnapshotJson(to),
+		Changes: changeOutputs,
+	})
+}
+
+func toSnapshotJson(snapshot *pkgdata.Snapshot) snapshotJson {
+	return snapshotJson{
+		Id:        snapshot.Id,
+		Timestamp: snapshot.Timestamp,
+		Packages:  len(snapshot.Pkgs),
+	}
+}
+
+// the current package set has no id
+func formatSnapshotLabel(snapshot *pkgdata.Snapshot, location *time.Location) string {
+	if snapshot.Id == 0 {
+		return "current packages"
+	}
+
+	return fmt.Sprintf(
+		"snapshot %d (%s)",
+		snapshot.Id,
+		time.Unix(snapshot.Timestamp, 0).In(location).Format(consts.DateTimeFormat),
+	)
+}
+
+func formatOptionalValue(value string) string {
+	if value == "" {
+		return "-"
+	}
+
+	return value
+}
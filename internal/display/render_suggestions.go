@@ -0,0 +1,97 @@
+package display
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"yaylog/internal/pkgdata"
+)
+
+type reasonSuggestionJson struct {
+	Name            string `json:"name"`
+	Reason          string `json:"reason"`
+	SuggestedReason string `json:"suggestedReason"`
+	Note            string `json:"note"`
+}
+
+func RenderReasonSuggestions(
+	pkgPtrs []*pkgdata.PkgInfo,
+	outputJson bool,
+	outputScript bool,
+	hasNoHeaders bool,
+) {
+	switch {
+	case outputJson:
+		manager.renderSuggestionsJson(pkgPtrs)
+	case outputScript:
+		manager.renderSuggestionsScript(pkgPtrs)
+	default:
+		manager.renderSuggestionsTable(pkgPtrs, hasNoHeaders)
+	}
+}
+
+func (o *OutputManager) renderSuggestionsTable(pkgPtrs []*pkgdata.PkgInfo, hasNoHeaders bool) {
+	o.clearProgress()
+
+	var buffer bytes.Buffer
+	w := tabwriter.NewWriter(&buffer, 0, 8, 2, ' ', 0)
+
+	if !hasNoHeaders {
+		fmt.Fprintln(w, "NAME\tREASON\tSUGGESTED\tNOTE")
+	}
+
+	for _, pkg := range pkgPtrs {
+		fmt.Fprintln(w, strings.Join([]string{pkg.Name, pkg.Reason, pkg.SuggestedReason, pkg.SuggestionNote}, "\t"))
+	}
+
+	w.Flush()
+	o.write(buffer.String())
+}
+
+func (o *OutputManager) renderSuggestionsJson(pkgPtrs []*pkgdata.PkgInfo) {
+	suggestions := make([]reasonSuggestionJson, len(pkgPtrs))
+	for i, pkg := range pkgPtrs {
+		suggestions[i] = reasonSuggestionJson{
+			Name:            pkg.Name,
+			Reason:          pkg.Reason,
+			SuggestedReason: pkg.SuggestedReason,
+			Note:            pkg.SuggestionNote,
+		}
+	}
+
+	o.writeJson(suggestions)
+}
+
+// the script is only printed, never executed. it is up to the user to review and run it
+func (o *OutputManager) renderSuggestionsScript(pkgPtrs []*pkgdata.PkgInfo) {
+	var buffer bytes.Buffer
+	var asDeps []string
+	var asExplicit []string
+
+	buffer.WriteString("#!/bin/sh\n")
+	buffer.WriteString("# install reason corrections suggested by yaylog\n")
+	buffer.WriteString("# review every line before running, e.g. with: sudo sh <file>\n\n")
+
+	for _, pkg := range pkgPtrs {
+		buffer.WriteString(fmt.Sprintf("# %s -> %s: %s\n", pkg.Name, pkg.SuggestedReason, pkg.SuggestionNote))
+
+		if pkg.SuggestedReason == "dependency" {
+			asDeps = append(asDeps, pkg.Name)
+		} else {
+			asExplicit = append(asExplicit, pkg.Name)
+		}
+	}
+
+	buffer.WriteString("\n")
+
+	if len(asDeps) > 0 {
+		buffer.WriteString("pacman -D --asdeps " + strings.Join(asDeps, " ") + "\n")
+	}
+
+	if len(asExplicit) > 0 {
+		buffer.WriteString("pacman -D --asexplicit " + strings.Join(asExplicit, " ") + "\n")
+	}
+
+	o.write(buffer.String())
+}
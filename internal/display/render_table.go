@@ -3,6 +3,7 @@ package display
 import (
 	"bytes"
 	"fmt"
+	"strconv"
 	"strings"
 	"text/tabwriter"
 	"time"
@@ -12,22 +13,32 @@ import (
 
 type tableContext struct {
 	DateFormat string
+	Location   *time.Location // dates are shown in this timezone
 }
 
 var columnHeaders = map[consts.FieldType]string{
-	consts.FieldDate:        "DATE",
-	consts.FieldName:        "NAME",
-	consts.FieldReason:      "REASON",
-	consts.FieldSize:        "SIZE",
-	consts.FieldVersion:     "VERSION",
-	consts.FieldDepends:     "DEPENDS",
-	consts.FieldRequiredBy:  "REQUIRED BY",
-	consts.FieldProvides:    "PROVIDES",
-	consts.FieldConflicts:   "CONFLICTS",
-	consts.FieldArch:        "ARCH",
-	consts.FieldLicense:     "LICENSE",
-	consts.FieldUrl:         "URL",
-	consts.FieldDescription: "DESCRIPTION",
+	consts.FieldDate:           "DATE",
+	consts.FieldName:           "NAME",
+	consts.FieldReason:         "REASON",
+	consts.FieldSize:           "SIZE",
+	consts.FieldVersion:        "VERSION",
+	consts.FieldDepends:        "DEPENDS",
+	consts.FieldRequiredBy:     "REQUIRED BY",
+	consts.FieldProvides:       "PROVIDES",
+	consts.FieldConflicts:      "CONFLICTS",
+	consts.FieldArch:           "ARCH",
+	consts.FieldLicense:        "LICENSE",
+	consts.FieldUrl:            "URL",
+	consts.FieldDescription:    "DESCRIPTION",
+	consts.FieldExclusiveDeps:  "EXCLUSIVE DEPS",
+	consts.FieldSharedDeps:     "SHARED DEPS",
+	consts.FieldExclusiveSize:  "EXCLUSIVE SIZE",
+	consts.FieldSharedSize:     "SHARED SIZE",
+	consts.FieldFirstInstalled: "FIRST INSTALLED",
+	consts.FieldLastUpgrade:    "LAST UPGRADE",
+	consts.FieldUpgradeCount:   "UPGRADES",
+	consts.FieldSession:        "SESSION",
+	consts.FieldRelevance:      "RELEVANCE",
 }
 
 // displays data in tab format
@@ -36,15 +47,11 @@ func (o *OutputManager) renderTable(
 	fields []consts.FieldType,
 	showFullTimestamp bool,
 	hasNoHeaders bool,
+	location *time.Location,
 ) {
 	o.clearProgress()
 
-	dateFormat := consts.DateOnlyFormat
-	if showFullTimestamp {
-		dateFormat = consts.DateTimeFormat
-	}
-
-	ctx := tableContext{DateFormat: dateFormat}
+	ctx := newTableContext(showFullTimestamp, location)
 
 	var buffer bytes.Buffer
 	w := tabwriter.NewWriter(&buffer, 0, 8, 2, ' ', 0)
@@ -61,6 +68,15 @@ func (o *OutputManager) renderTable(
 	o.write(buffer.String())
 }
 
+func newTableContext(showFullTimestamp bool, location *time.Location) tableContext {
+	dateFormat := consts.DateOnlyFormat
+	if showFullTimestamp {
+		dateFormat = consts.DateTimeFormat
+	}
+
+	return tableContext{DateFormat: dateFormat, Location: location}
+}
+
 func renderHeaders(w *tabwriter.Writer, fields []consts.FieldType) {
 	headers := make([]string, len(fields))
 	for i, field := range fields {
@@ -112,6 +128,19 @@ func getTableValue(pkg *pkgdata.PkgInfo, field consts.FieldType, ctx tableContex
 		return pkg.Url
 	case consts.FieldDescription:
 		return pkg.Description
+	case consts.FieldExclusiveDeps, consts.FieldSharedDeps,
+		consts.FieldExclusiveSize, consts.FieldSharedSize:
+		return formatDependencyOverlap(pkg, field)
+	case consts.FieldFirstInstalled:
+		return formatOptionalTimestamp(pkg.FirstInstalled, ctx)
+	case consts.FieldLastUpgrade:
+		return formatOptionalTimestamp(pkg.LastUpgrade, ctx)
+	case consts.FieldUpgradeCount:
+		return strconv.Itoa(pkg.UpgradeCount)
+	case consts.FieldSession:
+		return formatSession(pkg.Session)
+	case consts.FieldRelevance:
+		return strconv.Itoa(pkg.Relevance)
 	default:
 		return ""
 	}
@@ -119,10 +148,27 @@ func getTableValue(pkg *pkgdata.PkgInfo, field consts.FieldType, ctx tableContex
 
 // use time as parameter
 func formatDate(pkg *pkgdata.PkgInfo, ctx tableContext) string {
-	timestamp := time.Unix(pkg.Timestamp, 0)
+	timestamp := time.Unix(pkg.Timestamp, 0).In(ctx.Location)
 	return timestamp.Format(ctx.DateFormat)
 }
 
+func formatSession(sessionId int) string {
+	if sessionId == 0 {
+		return "-"
+	}
+
+	return strconv.Itoa(sessionId)
+}
+
+// zero means the event is not in the pacman log
+func formatOptionalTimestamp(timestamp int64, ctx tableContext) string {
+	if timestamp == 0 {
+		return "-"
+	}
+
+	return time.Unix(timestamp, 0).In(ctx.Location).Format(ctx.DateFormat)
+}
+
 func formatRelations(relations []pkgdata.Relation) string {
 	if len(relations) == 0 {
 		return "-"
@@ -136,15 +182,55 @@ func formatRelations(relations []pkgdata.Relation) string {
 	return strings.Join(pkgNameList, ", ")
 }
 
-func formatSize(size int64) string {
-	switch {
-	case size >= consts.GB:
-		return fmt.Sprintf("%.2f GB", float64(size)/(consts.GB))
-	case size >= consts.MB:
-		return fmt.Sprintf("%.2f MB", float64(size)/(consts.MB))
-	case size >= consts.KB:
-		return fmt.Sprintf("%.2f KB", float64(size)/(consts.KB))
+// overlap is only calculated for explicit packages
+func formatDependencyOverlap(pkg *pkgdata.PkgInfo, field consts.FieldType) string {
+	if !pkgdata.FilterExplicit(pkg) {
+		return "-"
+	}
+
+	switch field {
+	case consts.FieldExclusiveDeps:
+		return strconv.Itoa(pkg.ExclusiveDeps)
+	case consts.FieldSharedDeps:
+		return strconv.Itoa(pkg.SharedDeps)
+	case consts.FieldExclusiveSize:
+		return formatSize(pkg.ExclusiveSize)
+	case consts.FieldSharedSize:
+		return formatSize(pkg.SharedSize)
 	default:
-		return fmt.Sprintf("%d B", size)
+		return ""
 	}
 }
+
+type sizeUnit struct {
+	name  string
+	bytes int64
+}
+
+// largest first
+var (
+	iecSizeUnits = []sizeUnit{{"TiB", consts.TiB}, {"GiB", consts.GiB}, {"MiB", consts.MiB}, {"KiB", consts.KiB}}
+	siSizeUnits  = []sizeUnit{{"TB", consts.TB}, {"GB", consts.GB}, {"MB", consts.MB}, {"kB", consts.KB}}
+)
+
+// set once from --size-units, before anything is rendered
+var sizeUnits = iecSizeUnits
+
+func SetSizeUnits(units string) {
+	if units == consts.SizeUnitsSi {
+		sizeUnits = siSizeUnits
+		return
+	}
+
+	sizeUnits = iecSizeUnits
+}
+
+func formatSize(size int64) string {
+	for _, unit := range sizeUnits {
+		if size >= unit.bytes {
+			return fmt.Sprintf("%.2f %s", float64(size)/float64(unit.bytes), unit.name)
+		}
+	}
+
+	return fmt.Sprintf("%d B", size)
+}
@@ -0,0 +1,221 @@
+package display
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"yaylog/internal/pkgdata"
+)
+
+const (
+	barChar       = "█"
+	minBarWidth   = 10
+	trendColWidth = 40 // room taken by the other columns of the trend table
+)
+
+var sparkChars = []rune("▁▂▃▄▅▆▇█")
+
+type trendTotalsJson struct {
+	Packages int   `json:"packages"`
+	Size     int64 `json:"size"`
+}
+
+type trendPointJson struct {
+	Period    string                     `json:"period"`
+	Snapshot  int                        `json:"snapshot"`
+	Timestamp int64                      `json:"timestamp"`
+	Packages  int                        `json:"packages"`
+	Size      int64                      `json:"size"`
+	ByReason  map[string]trendTotalsJson `json:"byReason"`
+	ByRepo    map[string]trendTotalsJson `json:"byRepo"`
+}
+
+func RenderTrend(points []pkgdata.TrendPoint, outputJson bool, hasNoHeaders bool) {
+	if outputJson {
+		manager.renderTrendJson(points)
+		return
+	}
+
+	manager.renderTrendTable(points, hasNoHeaders)
+}
+
+func (o *OutputManager) renderTrendTable(points []pkgdata.TrendPoint, hasNoHeaders bool) {
+	o.clearProgress()
+
+	if len(points) == 0 {
+		o.writeLine("No snapshots recorded yet.")
+		return
+	}
+
+	var buffer bytes.Buffer
+	var maxSize int64
+	sizes := make([]int64, len(points))
+
+	for i, point := range points {
+		sizes[i] = point.Total.Size
+		maxSize = max(maxSize, point.Total.Size)
+	}
+
+	barWidth := max(o.terminalWidth-trendColWidth, minBarWidth)
+	w := tabwriter.NewWriter(&buffer, 0, 8, 2, ' ', 0)
+
+	if !hasNoHeaders {
+		fmt.Fprintln(w, "PERIOD\tPACKAGES\tSIZE\tCHANGE\t")
+	}
+
+	for i, point := range points {
+		change := "-"
+		if i > 0 {
+			change = formatSizeChange(point.Total.Size - points[i-1].Total.Size)
+		}
+
+		fmt.Fprintln(w, strings.Join([]string{
+			point.Period,
+			strconv.Itoa(point.Total.PkgCount),
+			formatSize(point.Total.Size),
+			change,
+			renderBar(point.Total.Size, maxSize, barWidth),
+		}, "\t"))
+	}
+
+	w.Flush()
+
+	if !hasNoHeaders {
+		buffer.WriteString("\nSize: " + renderSparkline(sizes) + "\n")
+	}
+
+	renderTrendBreakdown(&buffer, points, "SIZE BY REASON", []string{"explicit", "dependency"}, func(point pkgdata.TrendPoint) map[string]pkgdata.TrendTotals {
+		return point.ByReason
+	}, hasNoHeaders)
+
+	renderTrendBreakdown(&buffer, points, "SIZE BY REPO", collectRepos(points), func(point pkgdata.TrendPoint) map[string]pkgdata.TrendTotals {
+		return point.ByRepo
+	}, hasNoHeaders)
+
+	o.write(buffer.String())
+}
+
+func renderTrendBreakdown(
+	buffer *bytes.Buffer,
+	points []pkgdata.TrendPoint,
+	title string,
+	keys []string,
+	getTotals func(point pkgdata.TrendPoint) map[string]pkgdata.TrendTotals,
+	hasNoHeaders bool,
+) {
+	buffer.WriteString("\n")
+
+	if !hasNoHeaders {
+		buffer.WriteString(title + "\n")
+	}
+
+	w := tabwriter.NewWriter(buffer, 0, 8, 2, ' ', 0)
+
+	if !hasNoHeaders {
+		fmt.Fprintln(w, "PERIOD\t"+strings.ToUpper(strings.Join(keys, "\t")))
+	}
+
+	for _, point := range points {
+		row := []string{point.Period}
+		totals := getTotals(point)
+
+		for _, key := range keys {
+			row = append(row, formatSize(totals[key].Size))
+		}
+
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+
+	w.Flush()
+}
+
+// repos by name, with foreign and unknown packages last
+func collectRepos(points []pkgdata.TrendPoint) []string {
+	var repos []string
+	var trailing []string
+
+	for _, point := range points {
+		for repo := range point.ByRepo {
+			if slices.Contains(repos, repo) || slices.Contains(trailing, repo) {
+				continue
+			}
+
+			if repo == pkgdata.RepoForeign || repo == pkgdata.RepoUnknown {
+				trailing = append(trailing, repo)
+			} else {
+				repos = append(repos, repo)
+			}
+		}
+	}
+
+	slices.Sort(repos)
+	slices.Sort(trailing)
+
+	return append(repos, trailing...)
+}
+
+func renderBar(value int64, maxValue int64, width int) string {
+	if maxValue <= 0 {
+		return ""
+	}
+
+	return strings.Repeat(barChar, max(int(value*int64(width)/maxValue), 1))
+}
+
+// scaled between the lowest and highest value, so that small changes stay visible
+func renderSparkline(values []int64) string {
+	minValue, maxValue := slices.Min(values), slices.Max(values)
+	spark := make([]rune, len(values))
+
+	for i, value := range values {
+		level := 0
+		if maxValue > minValue {
+			level = int((value - minValue) * int64(len(sparkChars)-1) / (maxValue - minValue))
+		}
+
+		spark[i] = sparkChars[level]
+	}
+
+	return string(spark)
+}
+
+func formatSizeChange(change int64) string {
+	switch {
+	case change > 0:
+		return "+" + formatSize(change)
+	case change < 0:
+		return "-" + formatSize(-change)
+	default:
+		return "0 B"
+	}
+}
+
+func (o *OutputManager) renderTrendJson(points []pkgdata.TrendPoint) {
+	pointOutputs := make([]trendPointJson, len(points))
+
+	for i, point := range points {
+		pointOutputs[i] = trendPointJson{
+			Period:    point.Period,
+			Snapshot:  point.Snapshot.Id,
+			Timestamp: point.Snapshot.Timestamp,
+			Packages:  point.Total.PkgCount,
+			Size:      point.Total.Size,
+			ByReason:  toTrendTotalsJson(point.ByReason),
+			ByRepo:    toTrendTotalsJson(point.ByRepo),
+		}
+	}
+
+	o.writeJson(pointOutputs)
+}
+
+func toTrendTotalsJson(totalsByKey map[string]pkgdata.TrendTotals) map[string]trendTotalsJson {
+	totalsJson := make(map[string]trendTotalsJson, len(totalsByKey))
+	for key, totals := range totalsByKey {
+		totalsJson[key] = trendTotalsJson{Packages: totals.PkgCount, Size: totals.Size}
+	}
+
+	return totalsJson
+}
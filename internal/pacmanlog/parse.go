@@ -0,0 +1,180 @@
+package pacmanlog
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	LogPath = "/var/log/pacman.log"
+
+	timestampFormat       = "2006-01-02T15:04:05-0700"
+	legacyTimestampFormat = "2006-01-02 15:04" // pacman < 5.1, local time without seconds
+	alpmTag               = "[ALPM] "
+	transactionPrefix     = "transaction "
+	transactionStarted    = "transaction started"
+)
+
+type Action int
+
+const (
+	ActionInstalled Action = iota
+	ActionUpgraded
+	ActionDowngraded
+	ActionReinstalled
+	ActionRemoved
+)
+
+type Event struct {
+	Timestamp   int64
+	Name        string
+	Action      Action
+	FromVersion string // only set for upgrades and downgrades
+	Version     string // version after the event, or the version that was removed
+	Transaction int    // numbered in log order, 0 when the log has no transaction boundaries
+}
+
+type Result struct {
+	Events []Event
+	Err    error
+}
+
+var actionLookup = map[string]Action{
+	"installed":   ActionInstalled,
+	"upgraded":    ActionUpgraded,
+	"downgraded":  ActionDowngraded,
+	"reinstalled": ActionReinstalled,
+	"removed":     ActionRemoved,
+}
+
+// parses the log in the background, the channel receives exactly one result
+func ParseFileAsync(logPath string) <-chan Result {
+	resultChan := make(chan Result, 1)
+
+	go func() {
+		events, err := ParseFile(logPath)
+		resultChan <- Result{Events: events, Err: err}
+		close(resultChan)
+	}()
+
+	return resultChan
+}
+
+func ParseFile(logPath string) ([]Event, error) {
+	file, err := os.Open(logPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open pacman log: %v", err)
+	}
+
+	defer file.Close()
+
+	return Parse(file)
+}
+
+// events are returned in log order, which is chronological
+func Parse(reader io.Reader) ([]Event, error) {
+	var events []Event
+	transaction := 0
+	inTransaction := false
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		timestamp, message, ok := parseAlpmLine(scanner.Text())
+		if !ok {
+			continue
+		}
+
+		switch {
+		case message == transactionStarted:
+			transaction++
+			inTransaction = true
+		case strings.HasPrefix(message, transactionPrefix): // completed, failed, or interrupted
+			inTransaction = false
+		default:
+			event, ok := parseEvent(timestamp, message)
+			if !ok {
+				continue
+			}
+
+			if inTransaction {
+				event.Transaction = transaction
+			}
+
+			events = append(events, event)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read pacman log: %v", err)
+	}
+
+	return events, nil
+}
+
+// [2024-05-03T00:00:30+0200] [ALPM] upgraded python (3.12.2-1 -> 3.12.3-1)
+func parseAlpmLine(line string) (int64, string, bool) {
+	timestamp, rest, ok := parseTimestamp(line)
+	if !ok {
+		return 0, "", false
+	}
+
+	message, isAlpm := strings.CutPrefix(rest, alpmTag)
+	return timestamp, message, isAlpm
+}
+
+func parseEvent(timestamp int64, message string) (Event, bool) {
+	verb, rest, _ := strings.Cut(message, " ")
+	action, exists := actionLookup[verb]
+	if !exists {
+		return Event{}, false
+	}
+
+	name, versions, ok := strings.Cut(rest, " (")
+	if !ok || !strings.HasSuffix(versions, ")") {
+		return Event{}, false
+	}
+
+	event := Event{
+		Timestamp: timestamp,
+		Name:      name,
+		Action:    action,
+	}
+
+	versions = strings.TrimSuffix(versions, ")")
+	if fromVersion, toVersion, isChange := strings.Cut(versions, " -> "); isChange {
+		event.FromVersion = fromVersion
+		event.Version = toVersion
+	} else {
+		event.Version = versions
+	}
+
+	return event, true
+}
+
+func parseTimestamp(line string) (int64, string, bool) {
+	if !strings.HasPrefix(line, "[") {
+		return 0, "", false
+	}
+
+	rawTimestamp, rest, ok := strings.Cut(line[1:], "] ")
+	if !ok {
+		return 0, "", false
+	}
+
+	parsedTime, err := time.Parse(timestampFormat, rawTimestamp)
+	if err != nil {
+		// legacy timestamps were written in the system timezone, whatever timezone dates are shown in
+		parsedTime, err = time.ParseInLocation(legacyTimestampFormat, rawTimestamp, time.Local)
+		if err != nil {
+			return 0, "", false
+		}
+	}
+
+	return parsedTime.Unix(), rest, true
+}
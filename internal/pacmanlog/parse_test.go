@@ -0,0 +1,54 @@
+package pacmanlog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	log := strings.Join([]string{
+		"[2024-05-03T00:00:30+0200] [PACMAN] Running 'pacman -S python'",
+		"[2024-05-03T00:00:30+0200] [ALPM] transaction started",
+		"[2024-05-03T00:00:30+0200] [ALPM] installed python (3.12.2-1)",
+		"[2024-05-03T00:00:30+0200] [ALPM] transaction completed",
+		"[2024-05-20T10:00:00+0200] [ALPM] upgraded python (3.12.2-1 -> 3.12.3-1)",
+		"[2024-05-21T10:00:00+0200] [ALPM] downgraded python (3.12.3-1 -> 3.12.2-1)",
+		"[2024-05-22T10:00:00+0200] [ALPM-SCRIPTLET] removed nothing (1.0-1)",
+		"[2024-05-23T10:00:00+0200] [ALPM] removed python (3.12.2-1)",
+		"[2019-01-01 12:00] [ALPM] installed oldtool (0.9-1)",
+		"not a log line",
+	}, "\n")
+
+	events, err := Parse(strings.NewReader(log))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []Event{
+		{Timestamp: 1714687230, Name: "python", Action: ActionInstalled, Version: "3.12.2-1", Transaction: 1},
+		{Timestamp: 1716192000, Name: "python", Action: ActionUpgraded, FromVersion: "3.12.2-1", Version: "3.12.3-1"},
+		{Timestamp: 1716278400, Name: "python", Action: ActionDowngraded, FromVersion: "3.12.3-1", Version: "3.12.2-1"},
+		{Timestamp: 1716451200, Name: "python", Action: ActionRemoved, Version: "3.12.2-1"},
+		{Name: "oldtool", Action: ActionInstalled, Version: "0.9-1"},
+	}
+
+	if len(events) != len(expected) {
+		t.Fatalf("expected %d events, got %d: %+v", len(expected), len(events), events)
+	}
+
+	for i, event := range events {
+		// legacy timestamps are in local time, only check that they were parsed
+		if expected[i].Timestamp == 0 {
+			expected[i].Timestamp = event.Timestamp
+		}
+
+		if event != expected[i] {
+			t.Errorf("event %d: expected %+v, got %+v", i, expected[i], event)
+		}
+	}
+
+	summary := Summarize(events)["python"]
+	if summary.FirstInstalled != 1714687230 || summary.UpgradeCount != 1 || summary.LastUpgrade != 1716192000 {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+}
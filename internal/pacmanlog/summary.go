@@ -0,0 +1,33 @@
+package pacmanlog
+
+type PkgSummary struct {
+	FirstInstalled int64
+	LastUpgrade    int64
+	UpgradeCount   int
+}
+
+// collapses the event history into per-package summaries, keyed by package name.
+// only what is left in the log is known, a rotated log can start mid-history
+func Summarize(events []Event) map[string]*PkgSummary {
+	summaries := make(map[string]*PkgSummary)
+
+	for _, event := range events {
+		summary, exists := summaries[event.Name]
+		if !exists {
+			summary = &PkgSummary{}
+			summaries[event.Name] = summary
+		}
+
+		switch event.Action {
+		case ActionInstalled:
+			if summary.FirstInstalled == 0 {
+				summary.FirstInstalled = event.Timestamp
+			}
+		case ActionUpgraded:
+			summary.UpgradeCount++
+			summary.LastUpgrade = event.Timestamp
+		}
+	}
+
+	return summaries
+}
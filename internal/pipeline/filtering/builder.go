@@ -2,11 +2,15 @@ package filtering
 
 import (
 	"fmt"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 	"yaylog/internal/config"
 	"yaylog/internal/consts"
 	"yaylog/internal/pkgdata"
+	"yaylog/internal/timeexpr"
 )
 
 type (
@@ -14,51 +18,203 @@ type (
 	FilterCondition = pkgdata.FilterCondition
 )
 
-func QueriesToConditions(filterQueries map[consts.FieldType]string) (
+// what conditions are built with besides the query itself
+type queryContext struct {
+	pkgPtrs       []*PkgInfo // the unfiltered package set, for queries that refer to other packages
+	caseSensitive bool
+	location      *time.Location // dates are parsed in this timezone
+}
+
+// the and'ed parts of the query become separate conditions, ordered by efficiency
+func QueriesToConditions(
+	filterQuery *config.Query,
+	pkgPtrs []*PkgInfo,
+	caseSensitive bool,
+	location *time.Location,
+) (
 	[]*FilterCondition,
 	error,
 ) {
-	conditions := make([]*FilterCondition, 0, len(filterQueries))
-
-	for fieldType, value := range filterQueries {
-		var condition *FilterCondition
-		var err error
-
-		switch fieldType {
-		case consts.FieldDate:
-			condition, err = parseDateFilterCondition(value)
-		case consts.FieldSize:
-			condition, err = parseSizeFilterCondition(value)
-		case consts.FieldName, consts.FieldRequiredBy, consts.FieldDepends,
-			consts.FieldProvides, consts.FieldConflicts, consts.FieldArch, consts.FieldLicense:
-			condition, err = parsePackageFilterCondition(fieldType, value)
-		case consts.FieldReason:
-			condition, err = parseReasonFilterCondition(value)
-		default:
-			err = fmt.Errorf("unsupported filter type: %s", consts.FieldNameLookup[fieldType])
-		}
+	conjuncts := []*config.Query{filterQuery}
+	if filterQuery.Op == config.QueryAnd {
+		conjuncts = filterQuery.Children
+	}
+
+	ctx := queryContext{pkgPtrs: pkgPtrs, caseSensitive: caseSensitive, location: location}
+	conditions, err := queriesToConditions(conjuncts, ctx)
+	if err != nil {
+		return []*FilterCondition{}, err
+	}
 
+	return conditions, nil
+}
+
+// sorted in order of efficiency
+func queriesToConditions(queries []*config.Query, ctx queryContext) ([]*FilterCondition, error) {
+	conditions := make([]*FilterCondition, 0, len(queries))
+
+	for _, query := range queries {
+		condition, err := queryToCondition(query, ctx)
 		if err != nil {
-			return []*FilterCondition{}, err
+			return nil, err
 		}
 
 		conditions = append(conditions, condition)
 	}
 
-	// sort filters in order of efficiency
-	sort.Slice(conditions, func(i int, j int) bool {
+	sort.SliceStable(conditions, func(i int, j int) bool {
 		return conditions[i].FieldType < conditions[j].FieldType
 	})
 
 	return conditions, nil
 }
 
-func parsePackageFilterCondition(
-	fieldType consts.FieldType,
-	targetListInput string,
-) (*FilterCondition, error) {
-	targetList := strings.Split(targetListInput, ",")
-	return newPackageCondition(fieldType, targetList)
+// nested queries are evaluated as a single condition, as costly as their most costly field
+func queryToCondition(query *config.Query, ctx queryContext) (*FilterCondition, error) {
+	switch query.Op {
+	case config.QueryNot:
+		operand, err := queryToCondition(query.Children[0], ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return newNotCondition(operand), nil
+
+	case config.QueryAnd, config.QueryOr:
+		operands, err := queriesToConditions(query.Children, ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return newJunctionCondition(operands, query.Op == config.QueryAnd), nil
+
+	default:
+		return matchToCondition(query, ctx)
+	}
+}
+
+// != is the negated = match, comparisons become open-ended ranges
+func matchToCondition(query *config.Query, ctx queryContext) (*FilterCondition, error) {
+	switch {
+	case query.Operator == config.MatchNotEqual:
+		condition, err := equalToCondition(query, ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return newNotCondition(condition), nil
+
+	case query.Operator.IsComparison():
+		return parseComparisonCondition(query.Field, query.Operator, query.Value, ctx.location)
+
+	default:
+		return equalToCondition(query, ctx)
+	}
+}
+
+// also builds the conditions for ==, ~, ~= and regex matches, which only text fields take
+func equalToCondition(query *config.Query, ctx queryContext) (*FilterCondition, error) {
+	value := query.Value
+
+	if query.Transitive {
+		return parseTransitiveFilterCondition(query, ctx.pkgPtrs, ctx.caseSensitive), nil
+	}
+
+	switch query.Field {
+	case consts.FieldDate:
+		return parseDateFilterCondition(value, ctx.location)
+	case consts.FieldSize:
+		return parseSizeFilterCondition(value)
+	case consts.FieldName, consts.FieldRequiredBy, consts.FieldDepends,
+		consts.FieldProvides, consts.FieldConflicts, consts.FieldArch, consts.FieldLicense, consts.FieldDescription, consts.FieldUrl:
+		return parsePackageFilterCondition(query, ctx.caseSensitive)
+	case consts.FieldReason:
+		return parseReasonFilterCondition(value)
+	case consts.FieldSession:
+		return parseSessionFilterCondition(value, ctx.pkgPtrs)
+	default:
+		return nil, fmt.Errorf("unsupported filter type: %s", consts.FieldNameLookup[query.Field])
+	}
+}
+
+// plain = matches substrings of strings, but whole relation names
+var relationFields = []consts.FieldType{
+	consts.FieldRequiredBy,
+	consts.FieldDepends,
+	consts.FieldProvides,
+	consts.FieldConflicts,
+}
+
+func parsePackageFilterCondition(query *config.Query, caseSensitive bool) (*FilterCondition, error) {
+	mode := pkgdata.MatchSubstring
+
+	switch {
+	case query.IsRegex():
+		mode = pkgdata.MatchRegex
+	case query.Operator == config.MatchGlob:
+		mode = pkgdata.MatchGlob
+	case query.Operator == config.MatchFuzzy:
+		mode = pkgdata.MatchFuzzy
+	case query.Operator == config.MatchExact || slices.Contains(relationFields, query.Field):
+		mode = pkgdata.MatchExact
+	}
+
+	// a regex is taken as a whole, commas and all
+	targets := []string{query.Value}
+	if mode != pkgdata.MatchRegex {
+		targets = strings.Split(query.Value, ",")
+	}
+
+	matcher, err := pkgdata.NewStringMatcher(mode, targets, caseSensitive)
+	if err != nil {
+		return nil, err
+	}
+
+	return newPackageCondition(query.Field, matcher)
+}
+
+// required-by* matches what the targets pull in, depends* what pulls the targets in
+func parseTransitiveFilterCondition(query *config.Query, pkgPtrs []*PkgInfo, caseSensitive bool) *FilterCondition {
+	targets := strings.Split(query.Value, ",")
+	if !caseSensitive {
+		targets = matchNameCase(targets, pkgPtrs)
+	}
+
+	var matchedPkgs []*PkgInfo
+	if query.Field == consts.FieldRequiredBy {
+		matchedPkgs = pkgdata.TransitiveDependencies(pkgPtrs, targets, query.MaxDepth)
+	} else {
+		matchedPkgs = pkgdata.TransitiveDependents(pkgPtrs, targets, query.MaxDepth)
+	}
+
+	return newTransitiveCondition(query.Field, matchedPkgs)
+}
+
+// spells targets the way installed packages or their provides do, ignoring case
+// like the direct relation matchers
+func matchNameCase(targets []string, pkgPtrs []*PkgInfo) []string {
+	knownNames := make(map[string]string)
+	for _, pkg := range pkgPtrs {
+		for _, provided := range pkg.Provides {
+			knownNames[strings.ToLower(provided.Name)] = provided.Name
+		}
+	}
+
+	// installed names win over provides, same as when resolving them
+	for _, pkg := range pkgPtrs {
+		knownNames[strings.ToLower(pkg.Name)] = pkg.Name
+	}
+
+	spelled := make([]string, len(targets))
+	for i, target := range targets {
+		if name, exists := knownNames[strings.ToLower(target)]; exists {
+			spelled[i] = name
+		} else {
+			spelled[i] = target
+		}
+	}
+
+	return spelled
 }
 
 func parseReasonFilterCondition(installReason string) (*FilterCondition, error) {
@@ -69,9 +225,43 @@ func parseReasonFilterCondition(installReason string) (*FilterCondition, error)
 	return newReasonCondition(installReason), nil
 }
 
+// targets are session numbers or names of packages whose session should be matched
+func parseSessionFilterCondition(targetListInput string, pkgPtrs []*PkgInfo) (*FilterCondition, error) {
+	pkgSessions := make(map[string]int, len(pkgPtrs))
+	pkgNames := make([]string, len(pkgPtrs))
+	for i, pkg := range pkgPtrs {
+		pkgSessions[strings.ToLower(pkg.Name)] = pkg.Session
+		pkgNames[i] = pkg.Name
+	}
+
+	sessionIds := make(map[int]bool)
+
+	for _, target := range strings.Split(targetListInput, ",") {
+		if sessionId, err := strconv.Atoi(target); err == nil {
+			sessionIds[sessionId] = true
+			continue
+		}
+
+		sessionId, exists := pkgSessions[strings.ToLower(target)]
+		if !exists {
+			// an unknown name would otherwise just match nothing
+			err := fmt.Errorf("invalid session filter: no installed package named %q", target)
+			if similar := pkgdata.RankSimilar(target, pkgNames, maxSuggestions); len(similar) > 0 {
+				err = fmt.Errorf("%w, did you mean %s?", err, strings.Join(similar, ", "))
+			}
+
+			return nil, err
+		}
+
+		sessionIds[sessionId] = true
+	}
+
+	return newSessionCondition(sessionIds), nil
+}
+
 // TODO: we can merge parseDateFilterCondition and parseSizeFilterCondition into parseRangeFilterCondition
-func parseDateFilterCondition(value string) (*FilterCondition, error) {
-	dateFilter, err := parseDateFilter(value)
+func parseDateFilterCondition(value string, location *time.Location) (*FilterCondition, error) {
+	dateFilter, err := parseDateFilter(value, location)
 	if err != nil {
 		return nil, fmt.Errorf("invalid date filter: %v", err)
 	}
@@ -83,6 +273,54 @@ func parseDateFilterCondition(value string) (*FilterCondition, error) {
 	return newDateCondition(dateFilter), nil
 }
 
+// the value is a single date or size, covering [lower, upper) in the units the range filters compare
+func parseComparisonCondition(
+	fieldType consts.FieldType,
+	operator config.MatchOperator,
+	value string,
+	location *time.Location,
+) (*FilterCondition, error) {
+	if isRangeInput(fieldType, value, location) {
+		return nil, fmt.Errorf("invalid %s filter: ranges cannot be used with %s", consts.FieldNameLookup[fieldType], operator)
+	}
+
+	switch fieldType {
+	case consts.FieldDate:
+		// a date covers the whole day, a month the whole month
+		lower, upper, err := parseDateBounds(value, location)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date filter: %v", err)
+		}
+
+		return newDateCondition(comparisonToRange(operator, lower, upper)), nil
+
+	case consts.FieldSize:
+		sizeFilter, err := parseSizeFilter(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid size filter: %v", err)
+		}
+
+		// strict comparisons exclude the value itself, which rounding would let through
+		sizeRange := comparisonToRange(operator, sizeFilter.Start, sizeFilter.Start+1)
+		sizeRange.InBytes = sizeFilter.InBytes || operator == config.MatchGreater || operator == config.MatchLess
+
+		return newSizeCondition(sizeRange), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported comparison for %s", consts.FieldNameLookup[fieldType])
+	}
+}
+
+// timestamps contain colons as well
+func isRangeInput(fieldType consts.FieldType, value string, location *time.Location) bool {
+	if fieldType == consts.FieldDate {
+		_, _, isRange := timeexpr.SplitRange(value, time.Now(), location)
+		return isRange
+	}
+
+	return strings.Contains(value, ":")
+}
+
 func parseSizeFilterCondition(value string) (*FilterCondition, error) {
 	sizeFilter, err := parseSizeFilter(value)
 	if err != nil {
@@ -2,15 +2,20 @@ package filtering
 
 import (
 	"fmt"
+	"math"
 	"strings"
+	"yaylog/internal/config"
 	"yaylog/internal/consts"
 	"yaylog/internal/pkgdata"
 )
 
+const filterPhasePrefix = "Filtering by "
+
 type RangeSelector struct {
 	Start   int64
 	End     int64
 	IsExact bool
+	InBytes bool // sizes given in bytes are compared exactly instead of to 3 significant digits
 }
 
 type ExactFilter func(pkg *PkgInfo, target int64) bool
@@ -19,47 +24,51 @@ type RangeFilter func(pkg *PkgInfo, start int64, end int64) bool
 
 func newBaseCondition(fieldType consts.FieldType) FilterCondition {
 	return FilterCondition{
-		PhaseName: "Filtering by " + consts.FieldNameLookup[fieldType],
+		PhaseName: filterPhasePrefix + consts.FieldNameLookup[fieldType],
 		FieldType: fieldType,
 	}
 }
 
-func newPackageCondition(fieldType consts.FieldType, targets []string) (*FilterCondition, error) {
+func newPackageCondition(fieldType consts.FieldType, matcher pkgdata.StringMatcher) (*FilterCondition, error) {
 	conditionFilter := newBaseCondition(fieldType)
 	var filterFunc pkgdata.Filter
 
-	for i, target := range targets {
-		targets[i] = strings.ToLower(target)
-	}
-
 	switch fieldType {
 	case consts.FieldName:
 		filterFunc = func(pkg *PkgInfo) bool {
-			return pkgdata.FilterByStrings(pkg.Name, targets)
+			return pkgdata.FilterByStrings(pkg.Name, matcher)
 		}
 	case consts.FieldArch:
 		filterFunc = func(pkg *PkgInfo) bool {
-			return pkgdata.FilterByStrings(pkg.Arch, targets)
+			return pkgdata.FilterByStrings(pkg.Arch, matcher)
 		}
 	case consts.FieldLicense:
 		filterFunc = func(pkg *PkgInfo) bool {
-			return pkgdata.FilterByStrings(pkg.License, targets)
+			return pkgdata.FilterByStrings(pkg.License, matcher)
+		}
+	case consts.FieldDescription:
+		filterFunc = func(pkg *PkgInfo) bool {
+			return pkgdata.FilterByStrings(pkg.Description, matcher)
+		}
+	case consts.FieldUrl:
+		filterFunc = func(pkg *PkgInfo) bool {
+			return pkgdata.FilterByStrings(pkg.Url, matcher)
 		}
 	case consts.FieldRequiredBy:
 		filterFunc = func(pkg *PkgInfo) bool {
-			return pkgdata.FilterByRelation(pkg.RequiredBy, targets)
+			return pkgdata.FilterByRelation(pkg.RequiredBy, matcher)
 		}
 	case consts.FieldDepends:
 		filterFunc = func(pkg *PkgInfo) bool {
-			return pkgdata.FilterByRelation(pkg.Depends, targets)
+			return pkgdata.FilterByRelation(pkg.Depends, matcher)
 		}
 	case consts.FieldProvides:
 		filterFunc = func(pkg *PkgInfo) bool {
-			return pkgdata.FilterByRelation(pkg.Provides, targets)
+			return pkgdata.FilterByRelation(pkg.Provides, matcher)
 		}
 	case consts.FieldConflicts:
 		filterFunc = func(pkg *PkgInfo) bool {
-			return pkgdata.FilterByRelation(pkg.Conflicts, targets)
+			return pkgdata.FilterByRelation(pkg.Conflicts, matcher)
 		}
 	default:
 		return nil, fmt.Errorf("invalid field for package filter: %s", consts.FieldNameLookup[fieldType])
@@ -70,6 +79,35 @@ func newPackageCondition(fieldType consts.FieldType, targets []string) (*FilterC
 	return &conditionFilter, nil
 }
 
+// the matched packages are found up front, as they depend on the whole package set
+func newTransitiveCondition(fieldType consts.FieldType, matchedPkgs []*PkgInfo) *FilterCondition {
+	matched := make(map[*PkgInfo]bool, len(matchedPkgs))
+	for _, pkg := range matchedPkgs {
+		matched[pkg] = true
+	}
+
+	condition := newBaseCondition(fieldType)
+	condition.Filter = func(pkg *PkgInfo) bool {
+		return matched[pkg]
+	}
+
+	return &condition
+}
+
+// the inclusive range of values that compare to a value covering [lower, upper)
+func comparisonToRange(operator config.MatchOperator, lower int64, upper int64) RangeSelector {
+	switch operator {
+	case config.MatchGreater:
+		return RangeSelector{Start: upper, End: math.MaxInt64}
+	case config.MatchGreaterEqual:
+		return RangeSelector{Start: lower, End: math.MaxInt64}
+	case config.MatchLess:
+		return RangeSelector{Start: 0, End: lower - 1}
+	default:
+		return RangeSelector{Start: 0, End: upper - 1}
+	}
+}
+
 func newRangeCondition(
 	rangeSelector RangeSelector,
 	fieldType consts.FieldType,
@@ -103,6 +141,15 @@ func newDateCondition(dateFilter RangeSelector) *FilterCondition {
 }
 
 func newSizeCondition(sizeFilter RangeSelector) *FilterCondition {
+	if sizeFilter.InBytes {
+		return newRangeCondition(
+			sizeFilter,
+			consts.FieldSize,
+			pkgdata.FilterByExactSize,
+			pkgdata.FilterByExactSizeRange,
+		)
+	}
+
 	return newRangeCondition(
 		sizeFilter,
 		consts.FieldSize,
@@ -111,6 +158,44 @@ func newSizeCondition(sizeFilter RangeSelector) *FilterCondition {
 	)
 }
 
+func newNotCondition(operand *FilterCondition) *FilterCondition {
+	condition := newBaseCondition(operand.FieldType)
+	condition.PhaseName = filterPhasePrefix + "not " + strings.TrimPrefix(operand.PhaseName, filterPhasePrefix)
+	condition.Filter = func(pkg *PkgInfo) bool {
+		return !operand.Filter(pkg)
+	}
+
+	return &condition
+}
+
+// operands are expected in order of efficiency, evaluation stops at the first one that decides
+func newJunctionCondition(operands []*FilterCondition, isAnd bool) *FilterCondition {
+	condition := newBaseCondition(operands[len(operands)-1].FieldType)
+
+	fieldNames := make([]string, len(operands))
+	for i, operand := range operands {
+		fieldNames[i] = strings.TrimPrefix(operand.PhaseName, filterPhasePrefix)
+	}
+
+	separator := " or "
+	if isAnd {
+		separator = " and "
+	}
+
+	condition.PhaseName = filterPhasePrefix + strings.Join(fieldNames, separator)
+	condition.Filter = func(pkg *PkgInfo) bool {
+		for _, operand := range operands {
+			if operand.Filter(pkg) != isAnd {
+				return !isAnd
+			}
+		}
+
+		return isAnd
+	}
+
+	return &condition
+}
+
 func newReasonCondition(reason string) *FilterCondition {
 	condition := newBaseCondition(consts.FieldReason)
 	condition.Filter = func(pkg *PkgInfo) bool {
@@ -119,3 +204,12 @@ func newReasonCondition(reason string) *FilterCondition {
 
 	return &condition
 }
+
+func newSessionCondition(sessionIds map[int]bool) *FilterCondition {
+	condition := newBaseCondition(consts.FieldSession)
+	condition.Filter = func(pkg *PkgInfo) bool {
+		return pkgdata.FilterBySession(pkg, sessionIds)
+	}
+
+	return &condition
+}
@@ -2,13 +2,13 @@ package filtering
 
 import (
 	"fmt"
-	"regexp"
-	"strings"
+	"math"
 	"time"
-	"yaylog/internal/consts"
+	"yaylog/internal/timeexpr"
 )
 
-func parseDateFilter(dateFilterInput string) (RangeSelector, error) {
+// a date expression, or a start:end range of them where either end may be left open
+func parseDateFilter(dateFilterInput string, location *time.Location) (RangeSelector, error) {
 	if dateFilterInput == "" {
 		return RangeSelector{}, nil
 	}
@@ -17,49 +17,68 @@ func parseDateFilter(dateFilterInput string) (RangeSelector, error) {
 		return RangeSelector{}, fmt.Errorf("invalid date filter: ':' must be accompanied by a date")
 	}
 
-	pattern := `^(\d{4}-\d{2}-\d{2})?(?::(\d{4}-\d{2}-\d{2})?)?$`
-	re := regexp.MustCompile(pattern)
-	matches := re.FindStringSubmatch(dateFilterInput)
-	isExact := !strings.Contains(dateFilterInput, ":")
+	now := time.Now()
 
-	if matches == nil {
-		return RangeSelector{}, fmt.Errorf("invalid date filter format: %q", dateFilterInput)
+	startInput, endInput, isRange := timeexpr.SplitRange(dateFilterInput, now, location)
+	if !isRange {
+		span, err := timeexpr.Parse(dateFilterInput, now, location)
+		if err != nil {
+			return RangeSelector{}, err
+		}
+
+		return spanToRange(span), nil
 	}
 
-	start, err := parseDateMatch(matches[1], 0)
-	if err != nil {
-		return RangeSelector{}, err
+	dateFilter := RangeSelector{Start: 0, End: math.MaxInt64}
+
+	if startInput != "" {
+		span, err := timeexpr.Parse(startInput, now, location)
+		if err != nil {
+			return RangeSelector{}, err
+		}
+
+		dateFilter.Start = span.Start.Unix()
 	}
 
-	end, err := parseDateMatch(matches[2], time.Now().Unix())
-	if err != nil {
-		return RangeSelector{}, err
+	if endInput != "" {
+		span, err := timeexpr.Parse(endInput, now, location)
+		if err != nil {
+			return RangeSelector{}, err
+		}
+
+		dateFilter.End = spanEnd(span)
 	}
 
-	end += int64(time.Hour * 24 / time.Second)
+	return dateFilter, nil
+}
+
+// a relative time means since then, e.g. 7d is the last 7 days. days, months and years are
+// matched as a range, so that they cover the same stretch of time in any timezone
+func spanToRange(span timeexpr.Span) RangeSelector {
+	if span.IsRelative {
+		return RangeSelector{Start: span.Start.Unix(), End: math.MaxInt64}
+	}
 
-	return RangeSelector{
-		start,
-		end,
-		isExact,
-	}, nil
+	return RangeSelector{Start: span.Start.Unix(), End: spanEnd(span)}
 }
 
-func parseDateMatch(dateInput string, defaultDate int64) (int64, error) {
-	if dateInput == "" {
-		return defaultDate, nil
+// the last second of the span, inclusive like the range filters
+func spanEnd(span timeexpr.Span) int64 {
+	if span.IsInstant() {
+		return span.Start.Unix()
 	}
 
-	return parseValidDate(dateInput)
+	return span.End.Unix() - 1
 }
 
-func parseValidDate(dateInput string) (int64, error) {
-	parsedDate, err := time.Parse(consts.DateOnlyFormat, dateInput)
+// the span as [lower, upper) for comparisons, an instant covers its second
+func parseDateBounds(dateInput string, location *time.Location) (int64, int64, error) {
+	span, err := timeexpr.Parse(dateInput, time.Now(), location)
 	if err != nil {
-		return 0, err
+		return 0, 0, err
 	}
 
-	return parsedDate.Unix(), nil
+	return span.Start.Unix(), spanEnd(span) + 1, nil
 }
 
 func validateDateFilter(dateFilter RangeSelector) error {
@@ -18,8 +18,8 @@ func parseSizeFilter(sizeFilterInput string) (RangeSelector, error) {
 		return RangeSelector{}, fmt.Errorf("invalid size filter: ':' must be accompanied by a value")
 	}
 
-	// valid size format: "10MB", "5GB:", ":20KB", "1.5MB:2GB" (value + unit, optional range)
-	pattern := `(?i)^(?:(\d+(?:\.\d+)?)(B|KB|MB|GB))?(?::(?:(\d+(?:\.\d+)?)(B|KB|MB|GB))?)?$`
+	// valid size format: "10MB", "5GiB:", ":20KB", "1.5MB:2GB", "1048576" (value + optional unit, optional range)
+	pattern := `^(?:(\d+(?:\.\d+)?)([A-Za-z]*))?(?::(?:(\d+(?:\.\d+)?)([A-Za-z]*))?)?$`
 	re := regexp.MustCompile(pattern)
 	matches := re.FindStringSubmatch(sizeFilterInput)
 	isExact := !strings.Contains(sizeFilterInput, ":")
@@ -39,9 +39,10 @@ func parseSizeFilter(sizeFilterInput string) (RangeSelector, error) {
 	}
 
 	return RangeSelector{
-		start,
-		end,
-		isExact,
+		Start:   start,
+		End:     end,
+		IsExact: isExact,
+		InBytes: isByteSize(matches[1], matches[2]) && isByteSize(matches[3], matches[4]),
 	}, nil
 }
 
@@ -53,28 +54,37 @@ func parseSizeMatch(value string, unit string, defaultSize int64) (int64, error)
 	return parseSizeInBytes(value, unit)
 }
 
+// kB, MB, GB and TB are decimal, KiB, MiB, GiB and TiB binary, matched case-insensitively
+var sizeUnitBytes = map[string]float64{
+	"":    1,
+	"B":   1,
+	"KB":  consts.KB,
+	"MB":  consts.MB,
+	"GB":  consts.GB,
+	"TB":  consts.TB,
+	"KIB": consts.KiB,
+	"MIB": consts.MiB,
+	"GIB": consts.GiB,
+	"TIB": consts.TiB,
+}
+
 func parseSizeInBytes(valueInput string, unitInput string) (sizeInBytes int64, err error) {
 	value, err := strconv.ParseFloat(valueInput, 64) // parseFloat for fractional input e.g. ">2.5KB"
 	if err != nil {
 		return 0, fmt.Errorf("invalid size value")
 	}
 
-	unit := strings.ToUpper(unitInput)
-
-	switch unit {
-	case "KB":
-		sizeInBytes = int64(value * consts.KB)
-	case "MB":
-		sizeInBytes = int64(value * consts.MB)
-	case "GB":
-		sizeInBytes = int64(value * consts.GB)
-	case "B":
-		sizeInBytes = int64(value)
-	default:
-		return 0, fmt.Errorf("invalid size unit: %v", unit)
+	unitBytes, exists := sizeUnitBytes[strings.ToUpper(unitInput)]
+	if !exists {
+		return 0, fmt.Errorf("invalid size unit: %v, expected B, kB, MB, GB, TB, KiB, MiB, GiB or TiB", unitInput)
 	}
 
-	return sizeInBytes, nil
+	return int64(value * unitBytes), nil
+}
+
+// whole bytes ask for byte precision, a missing value of an open range does not count
+func isByteSize(value string, unit string) bool {
+	return value == "" || ((unit == "" || strings.EqualFold(unit, "B")) && !strings.Contains(value, "."))
 }
 
 func validateSizeFilter(sizeFilter RangeSelector) error {
@@ -0,0 +1,71 @@
+package filtering
+
+import (
+	"math"
+	"testing"
+	"time"
+	"yaylog/internal/config"
+	"yaylog/internal/consts"
+)
+
+func TestParseSizeFilter(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected RangeSelector
+	}{
+		{"1KiB", RangeSelector{Start: 1024, End: math.MaxInt64, IsExact: true}},
+		{"1kB", RangeSelector{Start: 1000, End: math.MaxInt64, IsExact: true}},
+		{"2GiB:1TiB", RangeSelector{Start: 2 << 30, End: 1 << 40}},
+		{"1.5GB:", RangeSelector{Start: 1_500_000_000, End: math.MaxInt64}},
+		{"1048576", RangeSelector{Start: 1 << 20, End: math.MaxInt64, IsExact: true, InBytes: true}},
+		{":500B", RangeSelector{Start: 0, End: 500, InBytes: true}},
+		{"1000B:1MB", RangeSelector{Start: 1000, End: 1_000_000}},
+	}
+
+	for _, c := range cases {
+		result, err := parseSizeFilter(c.input)
+		if err != nil {
+			t.Errorf("parseSizeFilter(%q): unexpected error: %v", c.input, err)
+			continue
+		}
+
+		if result != c.expected {
+			t.Errorf("parseSizeFilter(%q): expected %+v, got %+v", c.input, c.expected, result)
+		}
+	}
+
+	for _, input := range []string{"10XB", "MB", "1:2:3"} {
+		if _, err := parseSizeFilter(input); err == nil {
+			t.Errorf("parseSizeFilter(%q): expected an error", input)
+		}
+	}
+}
+
+func TestSizeComparison(t *testing.T) {
+	pkg := &PkgInfo{Size: 100_000_000}
+
+	cases := []struct {
+		operator config.MatchOperator
+		value    string
+		expected bool
+	}{
+		{config.MatchGreater, "100MB", false},
+		{config.MatchGreaterEqual, "100MB", true},
+		{config.MatchLess, "100MB", false},
+		{config.MatchLessEqual, "100MB", true},
+		{config.MatchGreater, "99999999", true},
+		{config.MatchLess, "100000001", true},
+	}
+
+	for _, c := range cases {
+		condition, err := parseComparisonCondition(consts.FieldSize, c.operator, c.value, time.UTC)
+		if err != nil {
+			t.Errorf("size%s%s: unexpected error: %v", c.operator, c.value, err)
+			continue
+		}
+
+		if result := condition.Filter(pkg); result != c.expected {
+			t.Errorf("size%s%s: expected %v for %d bytes, got %v", c.operator, c.value, c.expected, pkg.Size, result)
+		}
+	}
+}
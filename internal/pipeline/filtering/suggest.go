@@ -0,0 +1,132 @@
+package filtering
+
+import (
+	"fmt"
+	"strings"
+	"yaylog/internal/config"
+	"yaylog/internal/consts"
+	"yaylog/internal/pkgdata"
+)
+
+const maxSuggestions = 3
+
+// "did you mean" hints for name, depends and required-by values that match no package at all,
+// most likely typos. values that exist but were filtered out by other queries get none
+func SuggestSimilarNames(filterQuery *config.Query, pkgPtrs []*PkgInfo) []string {
+	pkgNames := make([]string, len(pkgPtrs))
+	for i, pkg := range pkgPtrs {
+		pkgNames[i] = pkg.Name
+	}
+
+	var dependNames []string
+	if filterQuery.HasField(consts.FieldDepends) {
+		dependNames = collectDependNames(pkgPtrs)
+	}
+
+	// transitive matches start from packages, found by name or by what they provide
+	var resolvableNames []string
+	if filterQuery.AnyMatch(func(match *config.Query) bool { return match.Transitive }) {
+		resolvableNames = append(collectProvidedNames(pkgPtrs), pkgNames...)
+	}
+
+	var suggestions []string
+
+	for _, query := range collectNameQueries(filterQuery) {
+		candidates := pkgNames
+		switch {
+		case query.Transitive:
+			candidates = resolvableNames
+		case query.Field == consts.FieldDepends:
+			candidates = dependNames
+		}
+
+		for _, value := range strings.Split(query.Value, ",") {
+			if matchesAnyName(query, value, candidates) {
+				continue
+			}
+
+			similar := pkgdata.RankSimilar(value, candidates, maxSuggestions)
+			if len(similar) == 0 {
+				continue
+			}
+
+			suggestions = append(suggestions, fmt.Sprintf(
+				"No package matches %s%s%s, did you mean %s?",
+				query.FieldLabel(),
+				query.Operator,
+				value,
+				strings.Join(similar, ", "),
+			))
+		}
+	}
+
+	return suggestions
+}
+
+// plain and exact matches on names, patterns are not expected to be typos
+func collectNameQueries(query *config.Query) []*config.Query {
+	if query == nil {
+		return nil
+	}
+
+	if query.Op != config.QueryMatch {
+		var nameQueries []*config.Query
+		for _, child := range query.Children {
+			nameQueries = append(nameQueries, collectNameQueries(child)...)
+		}
+
+		return nameQueries
+	}
+
+	switch query.Field {
+	case consts.FieldName, consts.FieldDepends, consts.FieldRequiredBy:
+	default:
+		return nil
+	}
+
+	if (query.Operator != config.MatchEqual && query.Operator != config.MatchExact) || query.IsRegex() {
+		return nil
+	}
+
+	return []*config.Query{query}
+}
+
+// names are matched as substrings by =, everything else as whole names
+func matchesAnyName(query *config.Query, value string, candidates []string) bool {
+	value = strings.ToLower(value)
+	isSubstring := query.Field == consts.FieldName && query.Operator == config.MatchEqual
+
+	for _, candidate := range candidates {
+		candidate = strings.ToLower(candidate)
+
+		if candidate == value || (isSubstring && strings.Contains(candidate, value)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func collectDependNames(pkgPtrs []*PkgInfo) []string {
+	return collectRelationNames(pkgPtrs, func(pkg *PkgInfo) []pkgdata.Relation { return pkg.Depends })
+}
+
+func collectProvidedNames(pkgPtrs []*PkgInfo) []string {
+	return collectRelationNames(pkgPtrs, func(pkg *PkgInfo) []pkgdata.Relation { return pkg.Provides })
+}
+
+func collectRelationNames(pkgPtrs []*PkgInfo, getRelations func(*PkgInfo) []pkgdata.Relation) []string {
+	seen := make(map[string]bool)
+	var names []string
+
+	for _, pkg := range pkgPtrs {
+		for _, relation := range getRelations(pkg) {
+			if !seen[relation.Name] {
+				seen[relation.Name] = true
+				names = append(names, relation.Name)
+			}
+		}
+	}
+
+	return names
+}
@@ -1,6 +1,27 @@
 package meta
 
+import "yaylog/internal/pacmanlog"
+
+// data computed from the whole package set, as a bit set
+type DerivedData int32
+
+const (
+	DerivedReverseDeps DerivedData = 1 << iota
+)
+
+func (derived DerivedData) Has(data DerivedData) bool {
+	return derived&data == data
+}
+
 type PipelineContext struct {
-	UsedCache     bool
+	UsedCache     bool         // the cached packages were all current, nothing was parsed
+	CachedDerived DerivedData  // derived data the loaded cache held
+	Derived       DerivedData  // derived data the packages currently hold
+	DecodeCache   func() error // set while the relations of cached packages are still encoded
+	FinishCache   func()       // set when the cache can only be saved once fresh packages were parsed in full
+	RacedRead     bool         // the database was locked or changed while it was read, nothing read from it is saved
 	IsInteractive bool
+	HistoryChan   <-chan pacmanlog.Result
+	History       *pacmanlog.Result // set once HistoryChan has been received from
+	Suggestions   []string          // shown when the queries left no packages
 }
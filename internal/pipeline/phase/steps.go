@@ -1,82 +1,549 @@
 package phase
 
 import (
+	"errors"
 	"fmt"
 	"slices"
+	"strings"
+	"time"
 	"yaylog/internal/config"
 	"yaylog/internal/consts"
 	out "yaylog/internal/display"
+	"yaylog/internal/pacmanlog"
 	"yaylog/internal/pipeline/filtering"
 	"yaylog/internal/pipeline/meta"
 	"yaylog/internal/pkgdata"
 )
 
+// cached packages are only loaded here, FetchStep checks which of them are still current.
+// a cache that cannot be used is not an error, the database is read in full instead
 func LoadCacheStep(
-	_ config.Config,
+	cfg config.Config,
 	_ []*PkgInfo,
-	_ ProgressReporter,
+	reportProgress ProgressReporter,
 	pipelineCtx *meta.PipelineContext,
 ) ([]*PkgInfo, error) {
-	pkgPtrs, err := pkgdata.LoadProtoCache()
-	if err == nil {
-		pipelineCtx.UsedCache = true
+	if cfg.NoCache || cfg.RebuildCache {
+		reportProgress(100, 100, "Cache skipped")
+		return nil, nil
 	}
 
-	// TODO: use ProgressReporter to report cache status
-	return pkgPtrs, nil
+	cache, err := pkgdata.LoadProtoCache()
+	if err != nil {
+		reportProgress(100, 100, describeCacheLoad(0, err))
+		return nil, nil
+	}
+
+	// relations are only decoded up front when they are displayed or filtered on,
+	// steps that compute from them decode them through ensureRelations
+	if needsRelations(cfg) {
+		if err = cache.DecodeRelations(); err != nil {
+			reportProgress(100, 100, describeCacheLoad(0, err))
+			return nil, nil
+		}
+	} else {
+		pipelineCtx.DecodeCache = cache.DecodeRelations
+	}
+
+	reportProgress(100, 100, describeCacheLoad(len(cache.Pkgs), nil))
+
+	pipelineCtx.CachedDerived = cache.Derived
+	pipelineCtx.Derived = cache.Derived
+
+	return cache.Pkgs, nil
 }
 
-// TODO: add progress reporting
-func FetchStep(
+func needsRelations(cfg config.Config) bool {
+	relationFields := []consts.FieldType{
+		consts.FieldDepends,
+		consts.FieldRequiredBy,
+		consts.FieldProvides,
+		consts.FieldConflicts,
+	}
+
+	for _, field := range relationFields {
+		if cfg.FilterQuery.HasField(field) {
+			return true
+		}
+	}
+
+	return needsAnyField(cfg, relationFields)
+}
+
+// decodes the relations of cached packages if that was deferred
+func ensureRelations(pipelineCtx *meta.PipelineContext) error {
+	if pipelineCtx.DecodeCache == nil {
+		return nil
+	}
+
+	err := pipelineCtx.DecodeCache()
+	pipelineCtx.DecodeCache = nil
+
+	if err != nil {
+		return fmt.Errorf("failed to decode cached relations, try --rebuild-cache: %v", err)
+	}
+
+	return nil
+}
+
+func describeCacheLoad(pkgCount int, err error) string {
+	switch {
+	case err == nil:
+		return fmt.Sprintf("Cache hit, %d packages", pkgCount)
+	case errors.Is(err, pkgdata.ErrCacheMissing):
+		return "Cache miss, no cache yet"
+	case errors.Is(err, pkgdata.ErrCacheVersion):
+		return "Cache miss, version mismatch"
+	case errors.Is(err, pkgdata.ErrCacheCorrupt):
+		return "Cache miss, cache is corrupt"
+	default:
+		return fmt.Sprintf("Cache miss, %v", err)
+	}
+}
+
+// replaces the installed package set with packages that have been removed according to pacman.log
+func RemovedPkgsStep(
 	_ config.Config,
-	pkgPtrs []*PkgInfo,
+	_ []*PkgInfo,
 	_ ProgressReporter,
+	_ *meta.PipelineContext,
+) ([]*PkgInfo, error) {
+	events, err := pacmanlog.ParseFile(pacmanlog.LogPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return pkgdata.RemovedPackages(events), nil
+}
+
+func FetchStep(
+	cfg config.Config,
+	cachedPkgs []*PkgInfo,
+	reportProgress ProgressReporter,
 	pipelineCtx *meta.PipelineContext,
 ) ([]*PkgInfo, error) {
-	if !pipelineCtx.UsedCache {
-		var err error
-		pkgPtrs, err = pkgdata.FetchPackages()
-		if err != nil {
-			out.WriteLine(fmt.Sprintf(
-				"Warning: Some packages may be missing due to corrupted package database: %v",
-				err,
-			))
+	// the log is parsed alongside fetching, later steps collect the result with awaitHistory
+	if needsPkgHistory(cfg) || needsSessions(cfg) || cfg.AsOf != 0 {
+		pipelineCtx.HistoryChan = pacmanlog.ParseFileAsync(pacmanlog.LogPath)
+	}
+
+	descFields := neededDescFields(cfg)
+	pkgPtrs, delta, err := fetchConsistently(cfg, cachedPkgs, descFields, reportProgress, pipelineCtx)
+	if errors.Is(err, pkgdata.ErrDbLocked) {
+		return nil, err
+	}
+
+	// listing no packages at all would look like an empty system
+	if err != nil {
+		return nil, fmt.Errorf("Error: %w", err)
+	}
+
+	// malformed entries are left out, the rest is still listed unless --strict is set
+	if len(delta.Diagnostics) > 0 {
+		if cfg.Strict {
+			return nil, fmt.Errorf("Error: %w:\n%s", pkgdata.ErrMalformedEntries, formatDiagnostics(delta.Diagnostics))
 		}
+
+		out.WriteLine(fmt.Sprintf(
+			"Warning: Skipped %d malformed package database entries, see yaylog doctor:\n%s",
+			len(delta.Diagnostics),
+			formatDiagnostics(delta.Diagnostics),
+		))
+	}
+
+	// cache staleness is per package, so it can only be reported once the database was read
+	if delta.Reused > 0 && !delta.IsEmpty() {
+		reportProgress(100, 100, fmt.Sprintf("Cache is stale, parsed %d changed packages", len(delta.Fresh)))
+	} else {
+		reportProgress(100, 100, fmt.Sprintf("Parsed %d packages, %d unchanged", len(delta.Fresh), delta.Reused))
+	}
+
+	// packages missing fields must not end up in the cache, they are parsed again in full meanwhile
+	if descFields != pkgdata.DescAll && len(delta.Fresh) > 0 && !cfg.NoCache && !pipelineCtx.RacedRead {
+		pipelineCtx.FinishCache = finishCacheLater(pkgPtrs, delta.Fresh, pipelineCtx)
+	}
+
+	if delta.Reused == 0 {
+		pipelineCtx.Derived = 0 // nothing cached was kept
+		return pkgPtrs, nil
+	}
+
+	pipelineCtx.UsedCache = delta.IsEmpty()
+	if pipelineCtx.UsedCache {
+		return pkgPtrs, nil
+	}
+
+	// reverse dependencies cannot be brought up to date without what they are derived from
+	if !descFields.Has(pkgdata.DescDepends | pkgdata.DescProvides) {
+		pipelineCtx.Derived &^= meta.DerivedReverseDeps
+		return pkgPtrs, nil
+	}
+
+	if err = ensureRelations(pipelineCtx); err != nil {
+		return nil, err
+	}
+
+	// cached reverse dependencies only need updating for what changed
+	if pipelineCtx.Derived.Has(meta.DerivedReverseDeps) && !pkgdata.UpdateReverseDependencies(pkgPtrs, delta) {
+		pipelineCtx.Derived &^= meta.DerivedReverseDeps
 	}
 
 	return pkgPtrs, nil
 }
 
+const maxFetchAttempts = 3
+
+// reads are retried while they race a transaction, as long as --on-lock is wait.
+// otherwise the result is kept but marked as raced, so that it is never saved
+func fetchConsistently(
+	cfg config.Config,
+	cachedPkgs []*PkgInfo,
+	descFields pkgdata.DescFields,
+	reportProgress ProgressReporter,
+	pipelineCtx *meta.PipelineContext,
+) ([]*PkgInfo, *pkgdata.PkgDelta, error) {
+	for attempt := 1; ; attempt++ {
+		if err := awaitDbLock(cfg, reportProgress); err != nil {
+			return nil, nil, err
+		}
+
+		state := pkgdata.ReadDbState()
+
+		pkgPtrs, delta, err := pkgdata.FetchPackages(cachedPkgs, descFields)
+		if err != nil || state.IsConsistent() {
+			return pkgPtrs, delta, err
+		}
+
+		if cfg.OnLock == config.OnLockWait && attempt < maxFetchAttempts {
+			reportProgress(attempt, maxFetchAttempts, "Package database changed while reading, retrying")
+			continue
+		}
+
+		pipelineCtx.RacedRead = true
+
+		if cfg.OnLock != config.OnLockProceed {
+			out.WriteLine("Warning: The package database was locked or changed while it was read, results may be inconsistent and are not cached")
+
+			if pkgdata.IsDbLocked() {
+				out.WriteLine(staleLockHint)
+			}
+		}
+
+		return pkgPtrs, delta, nil
+	}
+}
+
+// a crashed pacman leaves its lock behind, which holds back every later read
+var staleLockHint = fmt.Sprintf(
+	"If no pacman process is running, the lock is stale and can be removed with: sudo rm %s",
+	pkgdata.PacmanLockPath,
+)
+
+func awaitDbLock(cfg config.Config, reportProgress ProgressReporter) error {
+	if cfg.OnLock != config.OnLockWait || !pkgdata.IsDbLocked() {
+		return nil
+	}
+
+	reportProgress(0, 1, "Waiting for pacman to release the database lock")
+
+	if err := pkgdata.WaitForDbUnlock(cfg.LockTimeout); err != nil {
+		return fmt.Errorf("Error: %w. Use --on-lock=warn to read it anyway.\n%s", err, staleLockHint)
+	}
+
+	return nil
+}
+
+func formatDiagnostics(diagnostics []pkgdata.Diagnostic) string {
+	lines := make([]string, len(diagnostics))
+	for i, diagnostic := range diagnostics {
+		lines[i] = "  " + diagnostic.Error()
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// desc fields the query needs, anything computed from relations needs all of them
+func neededDescFields(cfg config.Config) pkgdata.DescFields {
+	if cfg.AsOf != 0 || cfg.Command == config.CommandOverlap || cfg.Command == config.CommandSuggest ||
+		needsDependencyOverlap(cfg) {
+		return pkgdata.DescAll
+	}
+
+	var descFields pkgdata.DescFields
+	if len(cfg.SearchTerms) > 0 {
+		descFields |= pkgdata.DescDescription
+	}
+
+	// transitive matches resolve dependencies through provides
+	if cfg.FilterQuery.AnyMatch(func(match *config.Query) bool { return match.Transitive }) {
+		descFields |= pkgdata.DescDepends | pkgdata.DescProvides
+	}
+
+	for field, fieldDescFields := range descFieldsByField {
+		if cfg.FilterQuery.HasField(field) || needsAnyField(cfg, []consts.FieldType{field}) {
+			descFields |= fieldDescFields
+		}
+	}
+
+	return descFields
+}
+
+var descFieldsByField = map[consts.FieldType]pkgdata.DescFields{
+	consts.FieldDepends:     pkgdata.DescDepends,
+	consts.FieldRequiredBy:  pkgdata.DescDepends | pkgdata.DescProvides,
+	consts.FieldProvides:    pkgdata.DescProvides,
+	consts.FieldConflicts:   pkgdata.DescConflicts,
+	consts.FieldArch:        pkgdata.DescArch,
+	consts.FieldLicense:     pkgdata.DescLicense,
+	consts.FieldUrl:         pkgdata.DescUrl,
+	consts.FieldDescription: pkgdata.DescDescription,
+}
+
+// starts parsing the projected packages in full and returns what saves the cache once that is done.
+// it runs after rendering, when nothing else touches the packages anymore, and still holds up exiting
+func finishCacheLater(
+	pkgPtrs []*PkgInfo,
+	projectedPkgs []*PkgInfo,
+	pipelineCtx *meta.PipelineContext,
+) func() {
+	state := pkgdata.ReadDbState()
+	fullParse := pkgdata.ParseFullAsync(projectedPkgs)
+
+	return func() {
+		// an entry that changed in the meantime is picked up by the next run instead
+		result := <-fullParse
+		if len(result.Diagnostics) > 0 || !state.IsConsistent() {
+			return
+		}
+
+		if err := ensureRelations(pipelineCtx); err != nil {
+			out.WriteLine(fmt.Sprintf("Warning: Error saving cache: %v", err))
+			return
+		}
+
+		fullByName := make(map[string]*PkgInfo, len(result.Pkgs))
+		for _, fullPkg := range result.Pkgs {
+			fullByName[fullPkg.Name] = fullPkg
+		}
+
+		cachePkgs := make([]*PkgInfo, len(pkgPtrs))
+		for i, pkg := range pkgPtrs {
+			cachePkgs[i] = pkg
+
+			if fullPkg, exists := fullByName[pkg.Name]; exists {
+				fullPkg.RequiredBy = pkg.RequiredBy // derived from fields that were parsed
+				cachePkgs[i] = fullPkg
+			}
+		}
+
+		err := pkgdata.SaveProtoCache(cachePkgs, pipelineCtx.Derived)
+		if err != nil {
+			out.WriteLine(fmt.Sprintf("Warning: Error saving cache: %v", err))
+		}
+	}
+}
+
+// computed lazily, only when the query needs them and the cache does not hold them yet
 func ReverseDepStep(
 	cfg config.Config,
 	pkgPtrs []*pkgdata.PkgInfo,
 	reportProgress ProgressReporter,
 	pipelineCtx *meta.PipelineContext,
 ) ([]*PkgInfo, error) {
-	if pipelineCtx.UsedCache {
+	if pipelineCtx.Derived.Has(meta.DerivedReverseDeps) || !needsReverseDeps(cfg) {
 		return pkgPtrs, nil
 	}
 
-	_, hasRequiredByFilter := cfg.FilterQueries[consts.FieldRequiredBy]
-	hasRequiredByField := slices.Contains(cfg.Fields, consts.FieldRequiredBy)
+	if err := ensureRelations(pipelineCtx); err != nil {
+		return nil, err
+	}
+
+	pipelineCtx.Derived |= meta.DerivedReverseDeps
+	return pkgdata.CalculateReverseDependencies(pkgPtrs, reportProgress)
+}
 
-	if !hasRequiredByField && !hasRequiredByFilter {
+func needsReverseDeps(cfg config.Config) bool {
+	return cfg.FilterQuery.HasField(consts.FieldRequiredBy) || needsAnyField(cfg, []consts.FieldType{consts.FieldRequiredBy})
+}
+
+// replaces the installed package set with the one reconstructed from pacman.log at cfg.AsOf.
+// later log events are dropped so that history fields are as of that moment too
+func AsOfStep(
+	cfg config.Config,
+	pkgPtrs []*PkgInfo,
+	reportProgress ProgressReporter,
+	pipelineCtx *meta.PipelineContext,
+) ([]*PkgInfo, error) {
+	if cfg.AsOf == 0 {
 		return pkgPtrs, nil
 	}
 
-	return pkgdata.CalculateReverseDependencies(pkgPtrs, reportProgress)
+	if err := ensureRelations(pipelineCtx); err != nil {
+		return nil, err
+	}
+
+	history := awaitHistory(pipelineCtx)
+	if history.Err != nil {
+		return nil, history.Err
+	}
+
+	history.Events = pkgdata.EventsUntil(history.Events, cfg.AsOf)
+
+	return pkgdata.ReconstructPackages(history.Events, pkgPtrs, reportProgress)
+}
+
+func HistoryStep(
+	_ config.Config,
+	pkgPtrs []*PkgInfo,
+	reportProgress ProgressReporter,
+	pipelineCtx *meta.PipelineContext,
+) ([]*PkgInfo, error) {
+	if pipelineCtx.HistoryChan == nil {
+		return pkgPtrs, nil
+	}
+
+	history := awaitHistory(pipelineCtx)
+	if history.Err != nil {
+		out.WriteLine(fmt.Sprintf("Warning: Package history is unavailable: %v", history.Err))
+		return pkgPtrs, nil
+	}
+
+	return pkgdata.ApplyPkgHistory(pkgPtrs, history.Events, reportProgress), nil
+}
+
+// the result can only be received once, so it is kept on the context for later steps
+func awaitHistory(pipelineCtx *meta.PipelineContext) *pacmanlog.Result {
+	if pipelineCtx.HistoryChan == nil {
+		return &pacmanlog.Result{Err: errors.New("pacman log was not read")}
+	}
+
+	if pipelineCtx.History == nil {
+		result := <-pipelineCtx.HistoryChan
+		pipelineCtx.History = &result
+	}
+
+	return pipelineCtx.History
+}
+
+func needsPkgHistory(cfg config.Config) bool {
+	return needsAnyField(cfg, []consts.FieldType{
+		consts.FieldFirstInstalled,
+		consts.FieldLastUpgrade,
+		consts.FieldUpgradeCount,
+	})
+}
+
+func SessionStep(
+	cfg config.Config,
+	pkgPtrs []*PkgInfo,
+	reportProgress ProgressReporter,
+	pipelineCtx *meta.PipelineContext,
+) ([]*PkgInfo, error) {
+	if !needsSessions(cfg) {
+		return pkgPtrs, nil
+	}
+
+	// without the log, sessions are still clustered by install time
+	var events []pacmanlog.Event
+	if history := awaitHistory(pipelineCtx); history.Err == nil {
+		events = history.Events
+	}
+
+	return pkgdata.AssignSessions(pkgPtrs, events, reportProgress), nil
+}
+
+func needsSessions(cfg config.Config) bool {
+	return cfg.FilterQuery.HasField(consts.FieldSession) || cfg.GroupBy == config.GroupBySession ||
+		needsAnyField(cfg, []consts.FieldType{consts.FieldSession})
+}
+
+func DependencyOverlapStep(
+	cfg config.Config,
+	pkgPtrs []*PkgInfo,
+	reportProgress ProgressReporter,
+	pipelineCtx *meta.PipelineContext,
+) ([]*PkgInfo, error) {
+	if cfg.Command != config.CommandOverlap && !needsDependencyOverlap(cfg) {
+		return pkgPtrs, nil
+	}
+
+	if err := ensureRelations(pipelineCtx); err != nil {
+		return nil, err
+	}
+
+	return pkgdata.CalculateDependencyOverlap(pkgPtrs, reportProgress)
+}
+
+func needsDependencyOverlap(cfg config.Config) bool {
+	return needsAnyField(cfg, []consts.FieldType{
+		consts.FieldExclusiveDeps,
+		consts.FieldSharedDeps,
+		consts.FieldExclusiveSize,
+		consts.FieldSharedSize,
+	})
+}
+
+// whether any of the fields are displayed or sorted by
+func needsAnyField(cfg config.Config, fields []consts.FieldType) bool {
+	for _, field := range fields {
+		if cfg.SortOption.Field == field || slices.Contains(cfg.Fields, field) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// narrows the package set down to install reason suggestions, before any filtering is applied
+func SuggestReasonStep(
+	cfg config.Config,
+	pkgPtrs []*PkgInfo,
+	reportProgress ProgressReporter,
+	pipelineCtx *meta.PipelineContext,
+) ([]*PkgInfo, error) {
+	if cfg.Command != config.CommandSuggest {
+		return pkgPtrs, nil
+	}
+
+	if err := ensureRelations(pipelineCtx); err != nil {
+		return nil, err
+	}
+
+	suggestions, skipped := pkgdata.SuggestReasons(pkgPtrs, reportProgress)
+	if len(skipped) > 0 {
+		out.WriteLine(fmt.Sprintf(
+			"Warning: Skipped %d packages whose file list could not be read:\n%s",
+			len(skipped),
+			formatDiagnostics(skipped),
+		))
+	}
+
+	return suggestions, nil
 }
 
 // TODO: add progress reporting
 func SaveCacheStep(
-	_ config.Config,
+	cfg config.Config,
 	pkgPtrs []*PkgInfo,
 	_ ProgressReporter,
 	pipelineCtx *meta.PipelineContext,
 ) ([]*PkgInfo, error) {
-	if !pipelineCtx.UsedCache {
+	// a raced read is never cached, and a projected one is saved by FinishCache instead
+	if pipelineCtx.RacedRead || cfg.NoCache || pipelineCtx.FinishCache != nil {
+		return pkgPtrs, nil
+	}
+
+	// an unchanged cache is still saved when derived data was computed for it
+	hasNewDerived := pipelineCtx.Derived&^pipelineCtx.CachedDerived != 0
+
+	if !pipelineCtx.UsedCache || hasNewDerived {
+		if err := ensureRelations(pipelineCtx); err != nil {
+			out.WriteLine(fmt.Sprintf("Warning: Error saving cache: %v", err))
+			return pkgPtrs, nil
+		}
+
 		// TODO: we can probably save the file concurrently
-		err := pkgdata.SaveProtoCache(pkgPtrs)
+		err := pkgdata.SaveProtoCache(pkgPtrs, pipelineCtx.Derived)
 		if err != nil {
 			out.WriteLine(fmt.Sprintf("Warning: Error saving cache: %v", err))
 		}
@@ -85,22 +552,64 @@ func SaveCacheStep(
 	return pkgPtrs, nil
 }
 
+// records the full package set before anything narrows it down
+func SnapshotStep(
+	cfg config.Config,
+	pkgPtrs []*PkgInfo,
+	_ ProgressReporter,
+	pipelineCtx *meta.PipelineContext,
+) ([]*PkgInfo, error) {
+	if !cfg.RecordSnapshot {
+		return pkgPtrs, nil
+	}
+
+	if pipelineCtx.RacedRead {
+		out.WriteLine("Warning: Snapshot not recorded, the package database was locked or changed while it was read")
+		return pkgPtrs, nil
+	}
+
+	_, _, err := pkgdata.SaveSnapshot(pkgPtrs, time.Now().Unix())
+	if err != nil {
+		out.WriteLine(fmt.Sprintf("Warning: Error saving snapshot: %v", err))
+	}
+
+	return pkgPtrs, nil
+}
+
+func SearchStep(
+	cfg config.Config,
+	pkgPtrs []*PkgInfo,
+	_ ProgressReporter,
+	_ *meta.PipelineContext,
+) ([]*PkgInfo, error) {
+	if len(cfg.SearchTerms) == 0 {
+		return pkgPtrs, nil
+	}
+
+	return pkgdata.SearchPackages(pkgPtrs, cfg.SearchTerms, cfg.CaseSensitive), nil
+}
+
 func FilterStep(
 	cfg config.Config,
 	pkgPtrs []*PkgInfo,
 	reportProgress ProgressReporter,
-	_ *meta.PipelineContext,
+	pipelineCtx *meta.PipelineContext,
 ) ([]*PkgInfo, error) {
-	if len(cfg.FilterQueries) == 0 {
+	if cfg.FilterQuery == nil {
 		return pkgPtrs, nil
 	}
 
-	filterConditions, err := filtering.QueriesToConditions(cfg.FilterQueries)
+	filterConditions, err := filtering.QueriesToConditions(cfg.FilterQuery, pkgPtrs, cfg.CaseSensitive, cfg.Location)
 	if err != nil {
 		return []*pkgdata.PkgInfo{}, err
 	}
 
-	return pkgdata.FilterPackages(pkgPtrs, filterConditions, reportProgress), nil
+	filteredPkgs := pkgdata.FilterPackages(pkgPtrs, filterConditions, reportProgress)
+	if len(filteredPkgs) == 0 {
+		pipelineCtx.Suggestions = filtering.SuggestSimilarNames(cfg.FilterQuery, pkgPtrs)
+	}
+
+	return filteredPkgs, nil
 }
 
 func SortStep(
@@ -4,16 +4,38 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"yaylog/internal/pipeline/meta"
 	pb "yaylog/internal/protobuf"
 
 	"google.golang.org/protobuf/proto"
 )
 
-const (
-	cachePath    = "/tmp/yaylog.cache"
-	cacheVersion = 2 // bump when updating structure of PkgInfo/Relation/pkginfo.proto
+const cacheVersion = 4 // bump when updating structure of PkgInfo/Relation/pkginfo.proto
+
+var (
+	ErrCacheMissing = errors.New("no cache yet")
+	ErrCacheCorrupt = errors.New("cache is corrupt")
+	ErrCacheVersion = errors.New("cache version mismatch")
 )
 
+type CacheInfo struct {
+	Path      string
+	Version   int32
+	Size      int64
+	PkgCount  int
+	DbModTime int64 // pacman DB mod time the cache was built against
+	IsStale   bool  // packages were added, removed, or changed since
+	Derived   meta.DerivedData
+}
+
+// packages loaded from the cache. their relations stay encoded until DecodeRelations,
+// most queries never look at them and decoding them dominates the load time
+type ProtoCache struct {
+	Pkgs      []*PkgInfo
+	Derived   meta.DerivedData
+	relations []byte
+}
+
 func getDbModTime() (int64, error) {
 	dirInfo, err := os.Stat(PacmanDbPath)
 	if err != nil {
@@ -23,53 +45,193 @@ func getDbModTime() (int64, error) {
 	return dirInfo.ModTime().Unix(), nil
 }
 
-func SaveProtoCache(pkgs []*PkgInfo) error {
+// derived records which derived data pkgs hold, anything else is computed again when needed
+func SaveProtoCache(pkgs []*PkgInfo, derived meta.DerivedData) error {
 	lastModified, err := getDbModTime()
 	if err != nil {
 		return err
 	}
 
+	relations, err := proto.Marshal(&pb.CachedRelations{Pkgs: pkgsToRelationProtos(pkgs)})
+	if err != nil {
+		return fmt.Errorf("failed to marshal cached relations: %v", err)
+	}
+
 	cachedPkgs := &pb.CachedPkgs{
 		Pkgs:         pkgsToProtos(pkgs),
 		LastModified: lastModified,
 		Version:      cacheVersion,
+		DerivedData:  int32(derived),
+		Relations:    relations,
 	}
 
 	byteData, err := proto.Marshal(cachedPkgs)
 	if err != nil {
-		return fmt.Errorf("failed to marshal cache: %v", cachedPkgs)
+		return fmt.Errorf("failed to marshal cache: %v", err)
 	}
 
-	return os.WriteFile(cachePath, byteData, 0644)
+	cachePath, err := getCachePath()
+	if err != nil {
+		return err
+	}
+
+	unlock, err := lockFile(cachePath, true)
+	if err != nil {
+		return err
+	}
+
+	defer unlock()
+
+	return writeFileAtomic(cachePath, byteData)
 }
 
-func LoadProtoCache() ([]*PkgInfo, error) {
-	byteData, err := os.ReadFile(cachePath)
+// relations are left encoded, see ProtoCache.DecodeRelations
+func LoadProtoCache() (*ProtoCache, error) {
+	cachedPkgs, _, err := readProtoCache()
 	if err != nil {
 		return nil, err
 	}
 
+	// staleness is checked per package when fetching, see FetchPackages
+	return &ProtoCache{
+		Pkgs:      protosToPkgs(cachedPkgs.Pkgs),
+		Derived:   meta.DerivedData(cachedPkgs.DerivedData),
+		relations: cachedPkgs.Relations,
+	}, nil
+}
+
+// fills in the relations of the cached packages, only the first call does any work.
+// packages keep their identity, so this can still be called once they were passed on
+func (cache *ProtoCache) DecodeRelations() error {
+	if cache.relations == nil {
+		return nil
+	}
+
+	var cachedRelations pb.CachedRelations
+	err := proto.Unmarshal(cache.relations, &cachedRelations)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrCacheCorrupt, err)
+	}
+
+	if len(cachedRelations.Pkgs) != len(cache.Pkgs) {
+		return fmt.Errorf(
+			"%w: found relations for %d packages, expected %d",
+			ErrCacheCorrupt,
+			len(cachedRelations.Pkgs),
+			len(cache.Pkgs),
+		)
+	}
+
+	for i, pbRels := range cachedRelations.Pkgs {
+		pkg := cache.Pkgs[i]
+		pkg.Depends = protosToRelations(pbRels.Depends)
+		pkg.RequiredBy = protosToRelations(pbRels.RequiredBy)
+		pkg.Provides = protosToRelations(pbRels.Provides)
+		pkg.Conflicts = protosToRelations(pbRels.Conflicts)
+	}
+
+	cache.relations = nil
+	return nil
+}
+
+// returns the cache along with its size on disk
+func readProtoCache() (*pb.CachedPkgs, int64, error) {
+	cachePath, err := getCachePath()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	unlock, err := lockFile(cachePath, false)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	defer unlock()
+
+	byteData, err := os.ReadFile(cachePath)
+	if os.IsNotExist(err) {
+		return nil, 0, ErrCacheMissing
+	}
+
+	if err != nil {
+		return nil, 0, err
+	}
+
 	var cachedPkgs pb.CachedPkgs
 	err = proto.Unmarshal(byteData, &cachedPkgs)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal cache: %v", err)
+		return nil, 0, fmt.Errorf("%w: %v", ErrCacheCorrupt, err)
 	}
 
 	if cachedPkgs.Version != cacheVersion {
-		return nil, errors.New("cache version mismatch, regenerating fresh cache")
+		return &cachedPkgs, int64(len(byteData)), fmt.Errorf(
+			"%w: found version %d, expected %d",
+			ErrCacheVersion,
+			cachedPkgs.Version,
+			cacheVersion,
+		)
 	}
 
-	dbModTime, err := getDbModTime()
+	return &cachedPkgs, int64(len(byteData)), nil
+}
+
+// describes the cache, Path is set even when the cache cannot be read
+func GetCacheInfo() (CacheInfo, error) {
+	cachePath, err := getCachePath()
 	if err != nil {
-		return nil, err
+		return CacheInfo{}, err
+	}
+
+	info := CacheInfo{Path: cachePath}
+
+	cachedPkgs, size, err := readProtoCache()
+	if cachedPkgs == nil {
+		return info, err
+	}
+
+	info.Version = cachedPkgs.Version
+	info.Size = size
+	info.PkgCount = len(cachedPkgs.Pkgs)
+	info.DbModTime = cachedPkgs.LastModified
+	info.Derived = meta.DerivedData(cachedPkgs.DerivedData)
+
+	if err != nil {
+		return info, err // the version is outdated, nothing else can be trusted
+	}
+
+	changes, err := findDbChanges(protosToPkgs(cachedPkgs.Pkgs))
+	if err != nil {
+		return info, err
 	}
 
-	if dbModTime > cachedPkgs.LastModified {
-		return nil, errors.New("cache is stale")
+	info.IsStale = len(changes.stale) > 0 || len(changes.descPaths) > 0
+	return info, nil
+}
+
+// returns whether there was a cache to remove
+func ClearProtoCache() (bool, error) {
+	cachePath, err := getCachePath()
+	if err != nil {
+		return false, err
+	}
+
+	unlock, err := lockFile(cachePath, true)
+	if err != nil {
+		return false, err
 	}
 
-	pkgs := protosToPkgs(cachedPkgs.Pkgs)
-	return pkgs, nil
+	defer unlock()
+
+	err = os.Remove(cachePath)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("failed to remove cache: %v", err)
+	}
+
+	return true, nil
 }
 
 func relationsToProtos(rels []Relation) []*pb.Relation {
@@ -98,16 +260,28 @@ func pkgsToProtos(pkgs []*PkgInfo) []*pb.PkgInfo {
 			License:     pkg.License,
 			Url:         pkg.Url,
 			Description: pkg.Description,
-			Depends:     relationsToProtos(pkg.Depends),
-			RequiredBy:  relationsToProtos(pkg.RequiredBy),
-			Provides:    relationsToProtos(pkg.Provides),
-			Conflicts:   relationsToProtos(pkg.Conflicts),
+			DescModTime: pkg.DescModTime,
 		}
 	}
 
 	return pbPkgs
 }
 
+// in the same order as pkgsToProtos, the relations section is matched up by index
+func pkgsToRelationProtos(pkgs []*PkgInfo) []*pb.PkgRelations {
+	pbRels := make([]*pb.PkgRelations, len(pkgs))
+	for i, pkg := range pkgs {
+		pbRels[i] = &pb.PkgRelations{
+			Depends:    relationsToProtos(pkg.Depends),
+			RequiredBy: relationsToProtos(pkg.RequiredBy),
+			Provides:   relationsToProtos(pkg.Provides),
+			Conflicts:  relationsToProtos(pkg.Conflicts),
+		}
+	}
+
+	return pbRels
+}
+
 func protosToRelations(pbRels []*pb.Relation) []Relation {
 	rels := make([]Relation, len(pbRels))
 	for i, pbRel := range pbRels {
@@ -134,10 +308,7 @@ func protosToPkgs(pbPkgs []*pb.PkgInfo) []*PkgInfo {
 			License:     pbPkg.License,
 			Url:         pbPkg.Url,
 			Description: pbPkg.Description,
-			Depends:     protosToRelations(pbPkg.Depends),
-			RequiredBy:  protosToRelations(pbPkg.RequiredBy),
-			Provides:    protosToRelations(pbPkg.Provides),
-			Conflicts:   protosToRelations(pbPkg.Conflicts),
+			DescModTime: pbPkg.DescModTime,
 		}
 	}
 
@@ -0,0 +1,104 @@
+package pkgdata
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+const lockExt = ".lock"
+
+// $XDG_CACHE_HOME/yaylog, or ~/.cache/yaylog
+func getCacheDir() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate user cache dir: %v", err)
+	}
+
+	return filepath.Join(cacheDir, "yaylog"), nil
+}
+
+// keyed by the database path, so that different databases never share a cache
+func getCachePath() (string, error) {
+	cacheDir, err := getCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	dbHash := sha256.Sum256([]byte(PacmanDbPath))
+	return filepath.Join(cacheDir, fmt.Sprintf("pkgs-%x.cache", dbHash[:8])), nil
+}
+
+// takes an advisory lock on path + ".lock", shared for readers and exclusive for writers.
+// the returned function releases it
+func lockFile(path string, exclusive bool) (func(), error) {
+	lockPath := path + lockExt
+	if err := os.MkdirAll(filepath.Dir(lockPath), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create cache dir: %v", err)
+	}
+
+	file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %v", err)
+	}
+
+	how := syscall.LOCK_SH
+	if exclusive {
+		how = syscall.LOCK_EX
+	}
+
+	for {
+		err = syscall.Flock(int(file.Fd()), how)
+		if !errors.Is(err, syscall.EINTR) {
+			break
+		}
+	}
+
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to lock %s: %v", filepath.Base(path), err)
+	}
+
+	return func() {
+		_ = syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+		file.Close()
+	}, nil
+}
+
+// readers either see the previous file or the complete new one, never a partial write
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create cache dir: %v", err)
+	}
+
+	tmpFile, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %v", err)
+	}
+
+	tmpPath := tmpFile.Name()
+
+	_, err = tmpFile.Write(data)
+	if err == nil {
+		err = tmpFile.Sync()
+	}
+
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Rename(tmpPath, path)
+	}
+
+	if err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write %s: %v", filepath.Base(path), err)
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package pkgdata
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	PacmanLockPath   = "/var/lib/pacman/db.lck"
+	lockPollInterval = 100 * time.Millisecond
+)
+
+var ErrDbLocked = errors.New("pacman database is locked")
+
+// what a read of the database is checked against once it is done
+type DbState struct {
+	ModTime  int64 // of the local database dir, changes whenever packages are added or removed
+	IsLocked bool  // pacman held the lock, a transaction may have been in progress
+}
+
+// pacman holds db.lck for as long as a transaction runs
+func IsDbLocked() bool {
+	_, err := os.Stat(PacmanLockPath)
+	return err == nil
+}
+
+// returns ErrDbLocked if the lock is still held once timeout passed
+func WaitForDbUnlock(timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+
+	for IsDbLocked() {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%w: %s still exists after %s", ErrDbLocked, PacmanLockPath, timeout)
+		}
+
+		time.Sleep(lockPollInterval)
+	}
+
+	return nil
+}
+
+func ReadDbState() DbState {
+	state := DbState{IsLocked: IsDbLocked()}
+
+	if dirInfo, err := os.Stat(PacmanDbPath); err == nil {
+		state.ModTime = dirInfo.ModTime().UnixNano()
+	}
+
+	return state
+}
+
+// whether a read that started at state could not have raced a transaction
+func (state DbState) IsConsistent() bool {
+	current := ReadDbState()
+	return !state.IsLocked && !current.IsLocked && current.ModTime == state.ModTime
+}
@@ -0,0 +1,119 @@
+package pkgdata
+
+import (
+	"fmt"
+	"yaylog/internal/pipeline/meta"
+)
+
+type OverlapMatrix struct {
+	Names  []string
+	Counts [][]int // Counts[i][i] is the total number of dependencies of Names[i]
+}
+
+// a dependency counts as shared when more than one explicit package pulls it in,
+// or when it was explicitly installed itself and would be kept regardless
+func CalculateDependencyOverlap(
+	pkgPtrs []*PkgInfo,
+	reportProgress meta.ProgressReporter,
+) ([]*PkgInfo, error) {
+	resolver := newPkgResolver(pkgPtrs)
+	explicitPkgs := make([]*PkgInfo, 0, len(pkgPtrs))
+
+	for _, pkg := range pkgPtrs {
+		if FilterExplicit(pkg) {
+			explicitPkgs = append(explicitPkgs, pkg)
+		}
+	}
+
+	total := len(explicitPkgs)
+	if total == 0 {
+		return pkgPtrs, nil
+	}
+
+	closures := make([][]*PkgInfo, total)
+	userCounts := make(map[*PkgInfo]int)
+
+	for i, pkg := range explicitPkgs {
+		closures[i] = resolver.transitiveDependencies(pkg)
+
+		for _, dep := range closures[i] {
+			userCounts[dep]++
+		}
+
+		if reportProgress != nil {
+			reportProgress(i+1, total, fmt.Sprintf("Resolved dependency tree %d/%d", i+1, total))
+		}
+	}
+
+	for i, pkg := range explicitPkgs {
+		applyDependencyOverlap(pkg, closures[i], userCounts)
+	}
+
+	return pkgPtrs, nil
+}
+
+func applyDependencyOverlap(pkg *PkgInfo, deps []*PkgInfo, userCounts map[*PkgInfo]int) {
+	pkg.ExclusiveDeps, pkg.SharedDeps = 0, 0
+	pkg.ExclusiveSize, pkg.SharedSize = 0, 0
+	pkg.TransitiveDeps = make([]string, len(deps))
+
+	for i, dep := range deps {
+		pkg.TransitiveDeps[i] = dep.Name
+
+		if userCounts[dep] > 1 || FilterExplicit(dep) {
+			pkg.SharedDeps++
+			pkg.SharedSize += dep.Size
+			continue
+		}
+
+		pkg.ExclusiveDeps++
+		pkg.ExclusiveSize += dep.Size
+	}
+}
+
+// pairwise count of common dependencies between the explicit packages in pkgPtrs.
+// expects CalculateDependencyOverlap to have been run on the full package set
+func BuildOverlapMatrix(pkgPtrs []*PkgInfo) OverlapMatrix {
+	var explicitPkgs []*PkgInfo
+	for _, pkg := range pkgPtrs {
+		if FilterExplicit(pkg) {
+			explicitPkgs = append(explicitPkgs, pkg)
+		}
+	}
+
+	depSets := make([]map[string]bool, len(explicitPkgs))
+	for i, pkg := range explicitPkgs {
+		depSets[i] = make(map[string]bool, len(pkg.TransitiveDeps))
+		for _, depName := range pkg.TransitiveDeps {
+			depSets[i][depName] = true
+		}
+	}
+
+	matrix := OverlapMatrix{
+		Names:  make([]string, len(explicitPkgs)),
+		Counts: make([][]int, len(explicitPkgs)),
+	}
+
+	for i, pkg := range explicitPkgs {
+		matrix.Names[i] = pkg.Name
+		matrix.Counts[i] = make([]int, len(explicitPkgs))
+	}
+
+	for i := range explicitPkgs {
+		matrix.Counts[i][i] = len(depSets[i])
+
+		for j := i + 1; j < len(explicitPkgs); j++ {
+			common := 0
+			for depName := range depSets[i] {
+				if depSets[j][depName] {
+					common++
+				}
+			}
+
+			matrix.Counts[i][j] = common
+			matrix.Counts[j][i] = common
+		}
+	}
+
+	return matrix
+}
@@ -0,0 +1,60 @@
+package pkgdata
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// returned when --strict turns diagnostics into a failure
+var ErrMalformedEntries = errors.New("malformed package database entries")
+
+// the database directory itself cannot be listed, so no package can be read
+var ErrDbUnreadable = errors.New("failed to read pacman database")
+
+// a problem with a single database entry, the package is left out of the results
+type Diagnostic struct {
+	Path   string
+	Line   int    // 0 when the problem is not tied to a line
+	Field  string // desc key such as %SIZE%, empty when not tied to a field
+	Reason string
+}
+
+func (diagnostic *Diagnostic) Error() string {
+	location := diagnostic.Path
+	if diagnostic.Line > 0 {
+		location = fmt.Sprintf("%s:%d", location, diagnostic.Line)
+	}
+
+	if diagnostic.Field != "" {
+		return fmt.Sprintf("%s: %s: %s", location, diagnostic.Field, diagnostic.Reason)
+	}
+
+	return fmt.Sprintf("%s: %s", location, diagnostic.Reason)
+}
+
+// parses every entry of the database in full, ignoring the cache.
+// returns the diagnostics sorted by path along with the number of entries checked
+func CheckDatabase() ([]Diagnostic, int, error) {
+	pkgPaths, err := os.ReadDir(PacmanDbPath)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", ErrDbUnreadable, err)
+	}
+
+	var descPaths []string
+	for _, packagePath := range pkgPaths {
+		if packagePath.IsDir() {
+			descPaths = append(descPaths, filepath.Join(PacmanDbPath, packagePath.Name(), "desc"))
+		}
+	}
+
+	_, diagnostics := parseDescFiles(descPaths, DescAll)
+
+	sort.Slice(diagnostics, func(i, j int) bool {
+		return diagnostics[i].Path < diagnostics[j].Path
+	})
+
+	return diagnostics, len(descPaths), nil
+}
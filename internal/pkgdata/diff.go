@@ -0,0 +1,68 @@
+package pkgdata
+
+import "sort"
+
+type ChangeType string
+
+const (
+	ChangeAdded      ChangeType = "added"
+	ChangeRemoved    ChangeType = "removed"
+	ChangeUpgraded   ChangeType = "upgraded"
+	ChangeDowngraded ChangeType = "downgraded"
+	ChangeReason     ChangeType = "reason"
+)
+
+// Old and New hold versions, or install reasons for reason changes
+type PkgChange struct {
+	Name   string
+	Change ChangeType
+	Old    string
+	New    string
+}
+
+// lists the changes between two package sets, ordered by name.
+// a package can both change version and reason, which results in two changes
+func DiffPackages(oldPkgs []*PkgInfo, newPkgs []*PkgInfo) []PkgChange {
+	oldByName := make(map[string]*PkgInfo, len(oldPkgs))
+	for _, pkg := range oldPkgs {
+		oldByName[pkg.Name] = pkg
+	}
+
+	newByName := make(map[string]*PkgInfo, len(newPkgs))
+	for _, pkg := range newPkgs {
+		newByName[pkg.Name] = pkg
+	}
+
+	var changes []PkgChange
+
+	for _, oldPkg := range oldPkgs {
+		if _, exists := newByName[oldPkg.Name]; !exists {
+			changes = append(changes, PkgChange{Name: oldPkg.Name, Change: ChangeRemoved, Old: oldPkg.Version})
+		}
+	}
+
+	for _, newPkg := range newPkgs {
+		oldPkg, exists := oldByName[newPkg.Name]
+		if !exists {
+			changes = append(changes, PkgChange{Name: newPkg.Name, Change: ChangeAdded, New: newPkg.Version})
+			continue
+		}
+
+		switch result := CompareVersions(newPkg.Version, oldPkg.Version); {
+		case result > 0:
+			changes = append(changes, PkgChange{newPkg.Name, ChangeUpgraded, oldPkg.Version, newPkg.Version})
+		case result < 0:
+			changes = append(changes, PkgChange{newPkg.Name, ChangeDowngraded, oldPkg.Version, newPkg.Version})
+		}
+
+		if newPkg.Reason != oldPkg.Reason {
+			changes = append(changes, PkgChange{newPkg.Name, ChangeReason, oldPkg.Reason, newPkg.Reason})
+		}
+	}
+
+	sort.SliceStable(changes, func(i int, j int) bool {
+		return changes[i].Name < changes[j].Name
+	})
+
+	return changes
+}
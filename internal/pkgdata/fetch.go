@@ -2,7 +2,6 @@ package pkgdata
 
 import (
 	"bytes"
-	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -29,18 +28,160 @@ const (
 	PacmanDbPath = "/var/lib/pacman/local"
 )
 
-func FetchPackages() ([]*PkgInfo, error) {
+// desc fields that are only parsed when asked for. the rest is always parsed,
+// it is cheap and needed to match packages up with the cache
+type DescFields uint8
+
+const (
+	DescDepends DescFields = 1 << iota
+	DescProvides
+	DescConflicts
+	DescArch
+	DescLicense
+	DescUrl
+	DescDescription
+
+	DescAll = DescDepends | DescProvides | DescConflicts | DescArch | DescLicense | DescUrl | DescDescription
+)
+
+var descFieldsByKey = map[string]DescFields{
+	fieldDepends:     DescDepends,
+	fieldProvides:    DescProvides,
+	fieldConflicts:   DescConflicts,
+	fieldArch:        DescArch,
+	fieldLicense:     DescLicense,
+	fieldUrl:         DescUrl,
+	fieldDescription: DescDescription,
+}
+
+func (fields DescFields) Has(field DescFields) bool {
+	return fields&field == field
+}
+
+// whether a desc key is parsed, keys that cannot be skipped always are
+func (fields DescFields) parses(key string) bool {
+	field, isOptional := descFieldsByKey[key]
+	return !isOptional || fields.Has(field)
+}
+
+type FullParseResult struct {
+	Pkgs        []*PkgInfo
+	Diagnostics []Diagnostic
+}
+
+// packages that changed between the cached packages and the database
+type PkgDelta struct {
+	Reused int        // cached packages that are still current
+	Fresh  []*PkgInfo // parsed in this fetch, either new or changed
+	Stale  []*PkgInfo // cached packages that were removed or changed
+
+	Diagnostics []Diagnostic // entries that could not be parsed, left out of Fresh
+}
+
+func (delta *PkgDelta) IsEmpty() bool {
+	return len(delta.Fresh) == 0 && len(delta.Stale) == 0
+}
+
+type dbChanges struct {
+	reused    []*PkgInfo
+	stale     []*PkgInfo
+	descPaths []string // desc files of new or changed packages
+}
+
+// only parses desc files that are missing from cachedPkgs or were modified since they were cached.
+// cached packages are matched by database directory (name-version) and desc mod time.
+// fields limits what is parsed, fresh packages only hold the fields asked for.
+// malformed entries do not fail the fetch, they are reported in the delta's diagnostics
+func FetchPackages(cachedPkgs []*PkgInfo, fields DescFields) ([]*PkgInfo, *PkgDelta, error) {
+	changes, err := findDbChanges(cachedPkgs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	freshPkgs, diagnostics := parseDescFiles(changes.descPaths, fields)
+
+	delta := &PkgDelta{
+		Reused:      len(changes.reused),
+		Fresh:       freshPkgs,
+		Stale:       changes.stale,
+		Diagnostics: diagnostics,
+	}
+
+	return append(changes.reused, freshPkgs...), delta, nil
+}
+
+func findDbChanges(cachedPkgs []*PkgInfo) (dbChanges, error) {
 	pkgPaths, err := os.ReadDir(PacmanDbPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read pacman database: %v", err)
+		return dbChanges{}, fmt.Errorf("%w: %v", ErrDbUnreadable, err)
 	}
 
-	numPkgs := len(pkgPaths)
+	cachedByDir := make(map[string]*PkgInfo, len(cachedPkgs))
+	for _, pkg := range cachedPkgs {
+		cachedByDir[pkg.Name+"-"+pkg.Version] = pkg
+	}
+
+	changes := dbChanges{reused: make([]*PkgInfo, 0, len(pkgPaths))}
+
+	for _, packagePath := range pkgPaths {
+		if !packagePath.IsDir() {
+			continue
+		}
+
+		descPath := filepath.Join(PacmanDbPath, packagePath.Name(), "desc")
+
+		if cachedPkg, exists := cachedByDir[packagePath.Name()]; exists {
+			delete(cachedByDir, packagePath.Name())
+
+			descInfo, err := os.Stat(descPath)
+			if err == nil && descInfo.ModTime().UnixNano() == cachedPkg.DescModTime {
+				changes.reused = append(changes.reused, cachedPkg)
+				continue
+			}
+
+			changes.stale = append(changes.stale, cachedPkg)
+		}
+
+		changes.descPaths = append(changes.descPaths, descPath)
+	}
+
+	// whatever is left was removed from the database
+	for _, cachedPkg := range cachedByDir {
+		changes.stale = append(changes.stale, cachedPkg)
+	}
+
+	return changes, nil
+}
+
+// parses the desc files of pkgs again with every field, without blocking the caller
+func ParseFullAsync(pkgs []*PkgInfo) <-chan FullParseResult {
+	resultChan := make(chan FullParseResult, 1)
+
+	descPaths := make([]string, len(pkgs))
+	for i, pkg := range pkgs {
+		descPaths[i] = filepath.Join(PacmanDbPath, pkg.Name+"-"+pkg.Version, "desc")
+	}
+
+	go func() {
+		fullPkgs, diagnostics := parseDescFiles(descPaths, DescAll)
+		resultChan <- FullParseResult{Pkgs: fullPkgs, Diagnostics: diagnostics}
+		close(resultChan)
+	}()
+
+	return resultChan
+}
+
+// parses what it can, entries that fail are returned as diagnostics instead
+func parseDescFiles(descPaths []string, fields DescFields) ([]*PkgInfo, []Diagnostic) {
+	numPkgs := len(descPaths)
+	if numPkgs == 0 {
+		return nil, nil
+	}
 
 	var wg sync.WaitGroup
 	descPathChan := make(chan string, numPkgs)
 	pkgPtrsChan := make(chan *PkgInfo, numPkgs)
-	errorsChan := make(chan error, numPkgs)
+	diagnosticsChan := make(chan *Diagnostic, numPkgs)
 
 	// fun fact: NumCPU() does account for hyperthreading
 	numWorkers := getWorkerCount(runtime.NumCPU(), numPkgs)
@@ -50,9 +191,9 @@ func FetchPackages() ([]*PkgInfo, error) {
 		go func() {
 			defer wg.Done()
 			for descPath := range descPathChan {
-				pkg, err := parseDescFile(descPath)
-				if err != nil {
-					errorsChan <- err
+				pkg, diagnostic := parseDescFile(descPath, fields)
+				if diagnostic != nil {
+					diagnosticsChan <- diagnostic
 					continue
 				}
 
@@ -61,27 +202,19 @@ func FetchPackages() ([]*PkgInfo, error) {
 		}()
 	}
 
-	for _, packagePath := range pkgPaths {
-		if packagePath.IsDir() {
-			descPath := filepath.Join(PacmanDbPath, packagePath.Name(), "desc")
-			descPathChan <- descPath
-		}
+	for _, descPath := range descPaths {
+		descPathChan <- descPath
 	}
 
 	close(descPathChan)
 
 	wg.Wait()
 	close(pkgPtrsChan)
-	close(errorsChan)
-
-	if len(errorsChan) > 0 {
-		var collectedErrors []error
-
-		for err := range errorsChan {
-			collectedErrors = append(collectedErrors, err)
-		}
+	close(diagnosticsChan)
 
-		return nil, errors.Join(collectedErrors...)
+	var diagnostics []Diagnostic
+	for diagnostic := range diagnosticsChan {
+		diagnostics = append(diagnostics, *diagnostic)
 	}
 
 	pkgPtrs := make([]*PkgInfo, 0, numPkgs)
@@ -89,7 +222,7 @@ func FetchPackages() ([]*PkgInfo, error) {
 		pkgPtrs = append(pkgPtrs, pkg)
 	}
 
-	return pkgPtrs, nil
+	return pkgPtrs, diagnostics
 }
 
 func getWorkerCount(numCPUs int, numFiles int) int {
@@ -109,21 +242,30 @@ func getWorkerCount(numCPUs int, numFiles int) int {
 	return min(numWorkers, 12) // avoid overthreading on high-core systems
 }
 
-func parseDescFile(descPath string) (*PkgInfo, error) {
+func parseDescFile(descPath string, fields DescFields) (*PkgInfo, *Diagnostic) {
 	file, err := os.Open(descPath)
+	if os.IsNotExist(err) {
+		return nil, &Diagnostic{Path: descPath, Reason: "desc file is missing"}
+	}
+
 	if err != nil {
-		return nil, fmt.Errorf("failed to open file: %v", err)
+		return nil, &Diagnostic{Path: descPath, Reason: fmt.Sprintf("failed to open file: %v", err)}
 	}
 
 	defer file.Close()
 
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return nil, &Diagnostic{Path: descPath, Reason: fmt.Sprintf("failed to stat file: %v", err)}
+	}
+
 	// the average desc file is 103.13 lines, reading the entire file into memory is more efficient than using bufio.Scanner
 	data, err := io.ReadAll(file)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read file: %w", err)
+		return nil, &Diagnostic{Path: descPath, Reason: fmt.Sprintf("failed to read file: %v", err)}
 	}
 
-	var pkg PkgInfo
+	pkg := PkgInfo{DescModTime: fileInfo.ModTime().UnixNano()}
 	var currentField string
 	start := 0
 	end := 0
@@ -137,12 +279,19 @@ func parseDescFile(descPath string) (*PkgInfo, error) {
 			case fieldName, fieldInstallDate, fieldSize, fieldReason,
 				fieldVersion, fieldArch, fieldLicense, fieldUrl, fieldDescription:
 				currentField = line
+				if !fields.parses(line) {
+					currentField = "" // its value is skipped as an unknown field
+				}
 
 			case fieldDepends, fieldProvides, fieldConflicts:
 				currentField = line
-				block, next := collectBlockBytes(data, end+1)
+				next := skipBlockBytes(data, end+1)
+
+				if fields.parses(line) {
+					block, _ := collectBlockBytes(data, end+1)
+					applyMultiLineField(&pkg, currentField, block)
+				}
 
-				applyMultiLineField(&pkg, currentField, block)
 				end = next
 				start = next
 
@@ -153,7 +302,12 @@ func parseDescFile(descPath string) (*PkgInfo, error) {
 
 			default:
 				if err := applySingleLineField(&pkg, currentField, line); err != nil {
-					return nil, fmt.Errorf("error reading desc file %s: %w", descPath, err)
+					return nil, &Diagnostic{
+						Path:   descPath,
+						Line:   bytes.Count(data[:start], []byte{'\n'}) + 1,
+						Field:  currentField,
+						Reason: err.Error(),
+					}
 				}
 			}
 
@@ -164,7 +318,7 @@ func parseDescFile(descPath string) (*PkgInfo, error) {
 	}
 
 	if pkg.Name == "" {
-		return nil, fmt.Errorf("package name is missing in file: %s", descPath)
+		return nil, &Diagnostic{Path: descPath, Field: fieldName, Reason: "package name is missing"}
 	}
 
 	if pkg.Reason == "" {
@@ -198,6 +352,27 @@ func collectBlockBytes(data []byte, start int) ([]string, int) {
 	return block, i
 }
 
+// finds the end of a block without converting its lines
+func skipBlockBytes(data []byte, start int) int {
+	i := start
+
+	for i < len(data) {
+		j := i
+
+		for j < len(data) && data[j] != '\n' {
+			j++
+		}
+
+		if len(bytes.TrimSpace(data[i:j])) == 0 {
+			break
+		}
+
+		i = j + 1
+	}
+
+	return i
+}
+
 func applySingleLineField(pkg *PkgInfo, field string, value string) error {
 	switch field {
 	case fieldName:
@@ -213,7 +388,7 @@ func applySingleLineField(pkg *PkgInfo, field string, value string) error {
 	case fieldInstallDate:
 		installDate, err := strconv.ParseInt(value, 10, 64)
 		if err != nil {
-			return fmt.Errorf("invalid install date value %q: %w", value, err)
+			return fmt.Errorf("invalid install date value %q", value)
 		}
 
 		pkg.Timestamp = installDate
@@ -224,7 +399,7 @@ func applySingleLineField(pkg *PkgInfo, field string, value string) error {
 	case fieldSize:
 		size, err := strconv.ParseInt(value, 10, 64)
 		if err != nil {
-			return fmt.Errorf("invalid size value %q: %w", value, err)
+			return fmt.Errorf("invalid size value %q", value)
 		}
 
 		pkg.Size = size
@@ -0,0 +1,70 @@
+package pkgdata
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const fieldFiles = "%FILES%"
+
+// pacman names local database entries after the package name and full version
+func pkgDbDir(pkg *PkgInfo) string {
+	return filepath.Join(PacmanDbPath, pkg.Name+"-"+pkg.Version)
+}
+
+// reads the paths of files installed by a package, directories end with a slash
+func readPkgFiles(pkg *PkgInfo) ([]string, *Diagnostic) {
+	filesPath := filepath.Join(pkgDbDir(pkg), "files")
+
+	data, err := os.ReadFile(filesPath)
+	if err != nil {
+		reason := err.Error()
+
+		var pathErr *os.PathError
+		if errors.As(err, &pathErr) {
+			reason = pathErr.Err.Error() // the path is already part of the diagnostic
+		}
+
+		return nil, &Diagnostic{Path: filesPath, Reason: "failed to read file list: " + reason}
+	}
+
+	var files []string
+	inFilesBlock := false
+
+	for _, rawLine := range bytes.Split(data, []byte("\n")) {
+		line := string(bytes.TrimSpace(rawLine))
+
+		switch {
+		case line == fieldFiles:
+			inFilesBlock = true
+		case line == "":
+			inFilesBlock = false
+		case inFilesBlock:
+			files = append(files, line)
+		}
+	}
+
+	return files, nil
+}
+
+// user-facing applications ship executables or desktop entries
+func hasApplicationFiles(files []string) bool {
+	for _, file := range files {
+		if strings.HasSuffix(file, "/") {
+			continue
+		}
+
+		if strings.HasPrefix(file, "usr/bin/") {
+			return true
+		}
+
+		if strings.HasPrefix(file, "usr/share/applications/") && strings.HasSuffix(file, ".desktop") {
+			return true
+		}
+	}
+
+	return false
+}
@@ -3,7 +3,6 @@ package pkgdata
 import (
 	"fmt"
 	"math"
-	"strings"
 	"sync"
 	"time"
 	"yaylog/internal/consts"
@@ -18,12 +17,10 @@ type FilterCondition struct {
 	FieldType consts.FieldType
 }
 
-func FilterByRelation(relations []Relation, targetNames []string) bool {
-	for _, targetName := range targetNames {
-		for _, relation := range relations {
-			if relation.Name == targetName {
-				return true
-			}
+func FilterByRelation(relations []Relation, matcher StringMatcher) bool {
+	for _, relation := range relations {
+		if matcher(relation.Name) {
+			return true
 		}
 	}
 
@@ -34,6 +31,10 @@ func FilterByReason(installReason string, targetReason string) bool {
 	return installReason == targetReason
 }
 
+func FilterBySession(pkg *PkgInfo, sessionIds map[int]bool) bool {
+	return sessionIds[pkg.Session]
+}
+
 func FilterExplicit(pkg *PkgInfo) bool {
 	return pkg.Reason == "explicit"
 }
@@ -62,7 +63,8 @@ func roundSizeInBytes(num int64) int64 {
 	numDigits := int(math.Log10(float64(num))) + 1
 	scaleFactor := int64(math.Pow10(numDigits - 3))
 
-	return num / scaleFactor
+	// the scale is kept, so that sizes of different magnitudes still compare in order
+	return num / scaleFactor * scaleFactor
 }
 
 // TODO: let's pre-round the inputs outside of these functions
@@ -75,16 +77,17 @@ func FilterBySizeRange(pkg *PkgInfo, startSize int64, endSize int64) bool {
 	return !(roundedSize < roundSizeInBytes(startSize) || roundedSize > roundSizeInBytes(endSize))
 }
 
-func FilterByStrings(pkgString string, targetStrings []string) bool {
-	pkgString = strings.ToLower(pkgString)
+// for sizes given in bytes
+func FilterByExactSize(pkg *PkgInfo, targetSize int64) bool {
+	return pkg.Size == targetSize
+}
 
-	for _, targetString := range targetStrings {
-		if strings.Contains(pkgString, targetString) {
-			return true
-		}
-	}
+func FilterByExactSizeRange(pkg *PkgInfo, startSize int64, endSize int64) bool {
+	return !(pkg.Size < startSize || pkg.Size > endSize)
+}
 
-	return false
+func FilterByStrings(pkgString string, matcher StringMatcher) bool {
+	return matcher(pkgString)
 }
 
 func FilterPackages(
@@ -0,0 +1,115 @@
+package pkgdata
+
+import (
+	"sort"
+	"strings"
+)
+
+// how many typos a term of a given length may contain
+func maxEditDistance(term string) int {
+	switch {
+	case len(term) <= 4:
+		return 1
+	case len(term) <= 8:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// a value matches a term that it contains, that abbreviates it (ffmpg for ffmpeg),
+// or that is a few typos away from it (firefix for firefox)
+func IsFuzzyMatch(term string, value string) bool {
+	if strings.Contains(value, term) || isAbbreviation(term, value) {
+		return true
+	}
+
+	return editDistance(term, value) <= maxEditDistance(term)
+}
+
+// the letters of term appear in value in order, starting with the same letter
+func isAbbreviation(term string, value string) bool {
+	if len(term) < 3 || len(value) == 0 || term[0] != value[0] {
+		return false
+	}
+
+	next := 0
+	for i := 0; i < len(value) && next < len(term); i++ {
+		if value[i] == term[next] {
+			next++
+		}
+	}
+
+	return next == len(term)
+}
+
+// the fewest insertions, deletions, substitutions and swaps of adjacent characters
+// that turn a into b
+func editDistance(a string, b string) int {
+	prevPrev := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			current[j] = min(prev[j]+1, current[j-1]+1, prev[j-1]+cost)
+
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				current[j] = min(current[j], prevPrev[j-2]+1)
+			}
+		}
+
+		prevPrev, prev, current = prev, current, prevPrev
+	}
+
+	return prev[len(b)]
+}
+
+// up to limit candidates that fuzzy match term, closest first. case is ignored
+func RankSimilar(term string, candidates []string, limit int) []string {
+	term = strings.ToLower(term)
+
+	type rankedCandidate struct {
+		value    string
+		distance int
+	}
+
+	seen := make(map[string]bool, len(candidates))
+	var ranked []rankedCandidate
+
+	for _, candidate := range candidates {
+		lowerCandidate := strings.ToLower(candidate)
+		if seen[candidate] || lowerCandidate == term || !IsFuzzyMatch(term, lowerCandidate) {
+			continue
+		}
+
+		seen[candidate] = true
+		ranked = append(ranked, rankedCandidate{candidate, editDistance(term, lowerCandidate)})
+	}
+
+	sort.Slice(ranked, func(i int, j int) bool {
+		if ranked[i].distance != ranked[j].distance {
+			return ranked[i].distance < ranked[j].distance
+		}
+
+		return ranked[i].value < ranked[j].value
+	})
+
+	similar := make([]string, 0, min(limit, len(ranked)))
+	for i := 0; i < len(ranked) && i < limit; i++ {
+		similar = append(similar, ranked[i].value)
+	}
+
+	return similar
+}
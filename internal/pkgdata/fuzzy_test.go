@@ -0,0 +1,37 @@
+package pkgdata
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestIsFuzzyMatch(t *testing.T) {
+	cases := []struct {
+		term     string
+		value    string
+		expected bool
+	}{
+		{"fox", "firefox", true},
+		{"firefix", "firefox", true},
+		{"fierfox", "firefox", true},
+		{"ffmpg", "ffmpeg", true},
+		{"pyreq", "python-requests", true},
+		{"vlc", "glibc", false},
+		{"firefox", "chromium", false},
+	}
+
+	for _, c := range cases {
+		if result := IsFuzzyMatch(c.term, c.value); result != c.expected {
+			t.Errorf("IsFuzzyMatch(%q, %q): expected %t, got %t", c.term, c.value, c.expected, result)
+		}
+	}
+}
+
+func TestRankSimilar(t *testing.T) {
+	candidates := []string{"python", "python-pip", "pyside6", "perl", "Python2"}
+
+	result := RankSimilar("pyhton", candidates, 2)
+	if expected := []string{"python", "Python2"}; !slices.Equal(result, expected) {
+		t.Errorf("RankSimilar: expected %q, got %q", expected, result)
+	}
+}
@@ -0,0 +1,128 @@
+package pkgdata
+
+// resolves relation names to installed packages, falling back to providers
+type pkgResolver struct {
+	pkgsByName map[string]*PkgInfo
+	providers  map[string]*PkgInfo
+}
+
+func newPkgResolver(pkgPtrs []*PkgInfo) *pkgResolver {
+	resolver := &pkgResolver{
+		pkgsByName: make(map[string]*PkgInfo, len(pkgPtrs)),
+		providers:  make(map[string]*PkgInfo),
+	}
+
+	for _, pkg := range pkgPtrs {
+		resolver.pkgsByName[pkg.Name] = pkg
+
+		for _, provided := range pkg.Provides {
+			resolver.providers[provided.Name] = pkg
+		}
+	}
+
+	return resolver
+}
+
+// an installed package of the same name takes precedence over a provider, same as pacman
+func (r *pkgResolver) resolve(name string) (*PkgInfo, bool) {
+	if pkg, exists := r.pkgsByName[name]; exists {
+		return pkg, true
+	}
+
+	pkg, exists := r.providers[name]
+	return pkg, exists
+}
+
+func (r *pkgResolver) directDependencies(pkg *PkgInfo) []*PkgInfo {
+	deps := make([]*PkgInfo, 0, len(pkg.Depends))
+	for _, depRelation := range pkg.Depends {
+		if dep, exists := r.resolve(depRelation.Name); exists {
+			deps = append(deps, dep)
+		}
+	}
+
+	return deps
+}
+
+// breadth-first walk of the dependency tree of pkg, excluding pkg itself
+func (r *pkgResolver) transitiveDependencies(pkg *PkgInfo) []*PkgInfo {
+	return walkGraph(pkg, r.directDependencies, 0)
+}
+
+// breadth-first walk from root along next, excluding root itself.
+// maxDepth limits how many steps are taken, 0 means no limit
+func walkGraph(root *PkgInfo, next func(*PkgInfo) []*PkgInfo, maxDepth int) []*PkgInfo {
+	visited := map[*PkgInfo]bool{root: true}
+	queue := []*PkgInfo{root}
+	var reached []*PkgInfo
+
+	for depth := 1; len(queue) > 0 && (maxDepth == 0 || depth <= maxDepth); depth++ {
+		var nextQueue []*PkgInfo
+
+		for _, current := range queue {
+			for _, neighbor := range next(current) {
+				if visited[neighbor] {
+					continue
+				}
+
+				visited[neighbor] = true
+				reached = append(reached, neighbor)
+				nextQueue = append(nextQueue, neighbor)
+			}
+		}
+
+		queue = nextQueue
+	}
+
+	return reached
+}
+
+// packages that the named packages pull in, directly or through other dependencies.
+// names resolve through provides, maxDepth limits the levels of dependencies, 0 means no limit
+func TransitiveDependencies(pkgPtrs []*PkgInfo, names []string, maxDepth int) []*PkgInfo {
+	resolver := newPkgResolver(pkgPtrs)
+	return walkGraphs(resolver.resolveAll(names), resolver.directDependencies, maxDepth)
+}
+
+// packages that depend on the named packages, directly or through other packages
+func TransitiveDependents(pkgPtrs []*PkgInfo, names []string, maxDepth int) []*PkgInfo {
+	resolver := newPkgResolver(pkgPtrs)
+
+	dependents := make(map[*PkgInfo][]*PkgInfo)
+	for _, pkg := range pkgPtrs {
+		for _, dep := range resolver.directDependencies(pkg) {
+			dependents[dep] = append(dependents[dep], pkg)
+		}
+	}
+
+	next := func(pkg *PkgInfo) []*PkgInfo { return dependents[pkg] }
+	return walkGraphs(resolver.resolveAll(names), next, maxDepth)
+}
+
+// every root is walked separately, so that a root reached from another root is included
+func walkGraphs(roots []*PkgInfo, next func(*PkgInfo) []*PkgInfo, maxDepth int) []*PkgInfo {
+	seen := make(map[*PkgInfo]bool)
+	var reached []*PkgInfo
+
+	for _, root := range roots {
+		for _, pkg := range walkGraph(root, next, maxDepth) {
+			if !seen[pkg] {
+				seen[pkg] = true
+				reached = append(reached, pkg)
+			}
+		}
+	}
+
+	return reached
+}
+
+func (r *pkgResolver) resolveAll(names []string) []*PkgInfo {
+	var pkgs []*PkgInfo
+	for _, name := range names {
+		if pkg, exists := r.resolve(name); exists {
+			pkgs = append(pkgs, pkg)
+		}
+	}
+
+	return pkgs
+}
@@ -0,0 +1,54 @@
+package pkgdata
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTransitiveRelations(t *testing.T) {
+	relations := func(names ...string) []Relation {
+		rels := make([]Relation, len(names))
+		for i, name := range names {
+			rels[i] = Relation{Name: name}
+		}
+
+		return rels
+	}
+
+	pkgs := []*PkgInfo{
+		{Name: "vlc", Depends: relations("ffmpeg", "qt6-base")},
+		{Name: "ffmpeg", Depends: relations("glibc", "libjpeg.so")},
+		{Name: "qt6-base", Depends: relations("glibc", "libjpeg")},
+		{Name: "libjpeg-turbo", Depends: relations("glibc"), Provides: relations("libjpeg", "libjpeg.so")},
+		{Name: "glibc"},
+	}
+
+	names := func(pkgPtrs []*PkgInfo) []string {
+		result := make([]string, len(pkgPtrs))
+		for i, pkg := range pkgPtrs {
+			result[i] = pkg.Name
+		}
+
+		slices.Sort(result)
+		return result
+	}
+
+	cases := []struct {
+		description string
+		result      []*PkgInfo
+		expected    []string
+	}{
+		{"dependencies of vlc", TransitiveDependencies(pkgs, []string{"vlc"}, 0), []string{"ffmpeg", "glibc", "libjpeg-turbo", "qt6-base"}},
+		{"direct dependencies of vlc", TransitiveDependencies(pkgs, []string{"vlc"}, 1), []string{"ffmpeg", "qt6-base"}},
+		{"dependencies of vlc and ffmpeg", TransitiveDependencies(pkgs, []string{"vlc", "ffmpeg"}, 1), []string{"ffmpeg", "glibc", "libjpeg-turbo", "qt6-base"}},
+		{"dependents of a provided name", TransitiveDependents(pkgs, []string{"libjpeg"}, 0), []string{"ffmpeg", "qt6-base", "vlc"}},
+		{"direct dependents of glibc", TransitiveDependents(pkgs, []string{"glibc"}, 1), []string{"ffmpeg", "libjpeg-turbo", "qt6-base"}},
+		{"unknown package", TransitiveDependents(pkgs, []string{"vlx"}, 0), []string{}},
+	}
+
+	for _, c := range cases {
+		if result := names(c.result); !slices.Equal(result, c.expected) {
+			t.Errorf("%s: expected %q, got %q", c.description, c.expected, result)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package pkgdata
+
+import (
+	"fmt"
+	"sort"
+	"yaylog/internal/pacmanlog"
+	"yaylog/internal/pipeline/meta"
+)
+
+func applySummary(pkg *PkgInfo, summary *pacmanlog.PkgSummary) {
+	pkg.FirstInstalled = summary.FirstInstalled
+	pkg.LastUpgrade = summary.LastUpgrade
+	pkg.UpgradeCount = summary.UpgradeCount
+}
+
+func ApplyPkgHistory(
+	pkgPtrs []*PkgInfo,
+	events []pacmanlog.Event,
+	reportProgress meta.ProgressReporter,
+) []*PkgInfo {
+	summaries := pacmanlog.Summarize(events)
+
+	for _, pkg := range pkgPtrs {
+		if summary, exists := summaries[pkg.Name]; exists {
+			applySummary(pkg, summary)
+		}
+	}
+
+	if reportProgress != nil {
+		reportProgress(100, 100, fmt.Sprintf("Applied %d log events", len(events)))
+	}
+
+	return pkgPtrs
+}
+
+// packages whose last logged event is a removal, dated by that removal.
+// only name, version and history are known for packages that are no longer installed
+func RemovedPackages(events []pacmanlog.Event) []*PkgInfo {
+	lastEvents := make(map[string]pacmanlog.Event)
+	var names []string // first-seen order keeps the output deterministic
+
+	for _, event := range events {
+		if _, exists := lastEvents[event.Name]; !exists {
+			names = append(names, event.Name)
+		}
+
+		lastEvents[event.Name] = event
+	}
+
+	summaries := pacmanlog.Summarize(events)
+	var pkgPtrs []*PkgInfo
+
+	for _, name := range names {
+		event := lastEvents[name]
+		if event.Action != pacmanlog.ActionRemoved {
+			continue
+		}
+
+		pkg := &PkgInfo{
+			Timestamp: event.Timestamp,
+			Name:      name,
+			Version:   event.Version,
+		}
+
+		applySummary(pkg, summaries[name])
+		pkgPtrs = append(pkgPtrs, pkg)
+	}
+
+	return pkgPtrs
+}
+
+// events are chronological, so everything after the cutoff can be dropped at once
+func EventsUntil(events []pacmanlog.Event, until int64) []pacmanlog.Event {
+	cutoff := sort.Search(len(events), func(i int) bool {
+		return events[i].Timestamp > until
+	})
+
+	return events[:cutoff]
+}
+
+// replays events to find the packages installed after the last one.
+// metadata is taken from currently installed packages: all of it when the version is unchanged,
+// only version-independent fields otherwise. reverse dependencies are recalculated for the past set
+func ReconstructPackages(
+	events []pacmanlog.Event,
+	currentPkgPtrs []*PkgInfo,
+	reportProgress meta.ProgressReporter,
+) ([]*PkgInfo, error) {
+	currentPkgs := make(map[string]*PkgInfo, len(currentPkgPtrs))
+	for _, pkg := range currentPkgPtrs {
+		currentPkgs[pkg.Name] = pkg
+	}
+
+	installed := make(map[string]pacmanlog.Event)
+	var names []string
+
+	for _, event := range events {
+		if event.Action == pacmanlog.ActionRemoved {
+			delete(installed, event.Name)
+			continue
+		}
+
+		if _, exists := installed[event.Name]; !exists {
+			names = append(names, event.Name)
+		}
+
+		installed[event.Name] = event
+	}
+
+	pkgPtrs := make([]*PkgInfo, 0, len(installed))
+	for _, name := range names {
+		event, exists := installed[name]
+		if !exists {
+			continue
+		}
+
+		pkgPtrs = append(pkgPtrs, pastPackage(event, currentPkgs[name]))
+		delete(installed, name) // names can repeat after a removal and reinstall
+	}
+
+	if reportProgress != nil {
+		reportProgress(100, 100, fmt.Sprintf("Reconstructed %d packages from %d events", len(pkgPtrs), len(events)))
+	}
+
+	return CalculateReverseDependencies(pkgPtrs, reportProgress)
+}
+
+func pastPackage(event pacmanlog.Event, currentPkg *PkgInfo) *PkgInfo {
+	pkg := &PkgInfo{
+		Timestamp: event.Timestamp, // like %INSTALLDATE%, the time of the last install or upgrade
+		Name:      event.Name,
+		Version:   event.Version,
+	}
+
+	if currentPkg == nil {
+		return pkg
+	}
+
+	pkg.Reason = currentPkg.Reason
+	pkg.Arch = currentPkg.Arch
+	pkg.License = currentPkg.License
+	pkg.Url = currentPkg.Url
+	pkg.Description = currentPkg.Description
+
+	if currentPkg.Version == event.Version {
+		pkg.Size = currentPkg.Size
+		pkg.Depends = currentPkg.Depends
+		pkg.Provides = currentPkg.Provides
+		pkg.Conflicts = currentPkg.Conflicts
+	}
+
+	return pkg
+}
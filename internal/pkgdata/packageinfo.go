@@ -31,4 +31,28 @@ type PkgInfo struct {
 	RequiredBy  []Relation
 	Provides    []Relation
 	Conflicts   []Relation
+	DescModTime int64 // used to find packages changed since they were cached
+
+	// from the sync databases, only recorded in snapshots
+	Repo string
+
+	// from pacman.log, not cached
+	FirstInstalled int64
+	LastUpgrade    int64
+	UpgradeCount   int
+	Session        int // 0 until sessions are assigned
+
+	// computed for explicit packages only, not cached
+	ExclusiveDeps  int
+	SharedDeps     int
+	ExclusiveSize  int64
+	SharedSize     int64
+	TransitiveDeps []string
+
+	// set by the suggest command
+	SuggestedReason string
+	SuggestionNote  string
+
+	// set by --search
+	Relevance int
 }
@@ -0,0 +1,74 @@
+package pkgdata
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"yaylog/internal/pipeline/meta"
+)
+
+// returns only the packages whose install reason looks wrong, with SuggestedReason and SuggestionNote set.
+// explicit packages required by other explicit packages could be dependencies,
+// unrequired dependencies that ship executables or desktop entries could be explicit.
+// packages whose file list cannot be read are skipped and returned as diagnostics
+func SuggestReasons(
+	pkgPtrs []*PkgInfo,
+	reportProgress meta.ProgressReporter,
+) ([]*PkgInfo, []Diagnostic) {
+	resolver := newPkgResolver(pkgPtrs)
+	requiredBy := make(map[*PkgInfo][]string)
+
+	for _, pkg := range pkgPtrs {
+		for _, depRelation := range pkg.Depends {
+			dep, exists := resolver.resolve(depRelation.Name)
+			if !exists || dep == pkg || slices.Contains(requiredBy[dep], pkg.Name) {
+				continue
+			}
+
+			requiredBy[dep] = append(requiredBy[dep], pkg.Name)
+		}
+	}
+
+	var suggestions []*PkgInfo
+	var skipped []Diagnostic
+	total := len(pkgPtrs)
+
+	for i, pkg := range pkgPtrs {
+		switch {
+		case FilterExplicit(pkg):
+			if explicitUsers := filterExplicitNames(requiredBy[pkg], resolver); len(explicitUsers) > 0 {
+				pkg.SuggestedReason = "dependency"
+				pkg.SuggestionNote = "required by explicit " + strings.Join(explicitUsers, ", ")
+				suggestions = append(suggestions, pkg)
+			}
+
+		case len(requiredBy[pkg]) == 0:
+			files, diagnostic := readPkgFiles(pkg)
+			if diagnostic != nil {
+				skipped = append(skipped, *diagnostic)
+			} else if hasApplicationFiles(files) {
+				pkg.SuggestedReason = "explicit"
+				pkg.SuggestionNote = "not required by any package, ships an application"
+				suggestions = append(suggestions, pkg)
+			}
+		}
+
+		if reportProgress != nil {
+			reportProgress(i+1, total, fmt.Sprintf("Checked %d/%d packages", i+1, total))
+		}
+	}
+
+	return suggestions, skipped
+}
+
+func filterExplicitNames(names []string, resolver *pkgResolver) []string {
+	var explicitNames []string
+
+	for _, name := range names {
+		if pkg, exists := resolver.pkgsByName[name]; exists && FilterExplicit(pkg) {
+			explicitNames = append(explicitNames, name)
+		}
+	}
+
+	return explicitNames
+}
@@ -0,0 +1,173 @@
+package pkgdata
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	PacmanSyncDbPath = "/var/lib/pacman/sync"
+	pacmanConfPath   = "/etc/pacman.conf"
+	syncDbExt        = ".db"
+
+	RepoForeign = "foreign" // installed, but in none of the sync repos (e.g. AUR)
+)
+
+// sets Repo on every package from the sync databases, in pacman.conf priority order.
+// without readable sync databases the repo is left empty, as it is unknown
+func AssignRepos(pkgPtrs []*PkgInfo) error {
+	repoByName, err := loadSyncRepos()
+	if err != nil {
+		return err
+	}
+
+	if len(repoByName) == 0 {
+		return nil
+	}
+
+	for _, pkg := range pkgPtrs {
+		repo, exists := repoByName[pkg.Name]
+		if !exists {
+			repo = RepoForeign
+		}
+
+		pkg.Repo = repo
+	}
+
+	return nil
+}
+
+func loadSyncRepos() (map[string]string, error) {
+	repos, err := getRepoOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	repoByName := make(map[string]string)
+
+	for _, repo := range repos {
+		names, err := readSyncDbNames(filepath.Join(PacmanSyncDbPath, repo+syncDbExt))
+		if os.IsNotExist(err) {
+			continue // configured but never synced
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		// the first repo in pacman.conf wins, like it does for pacman
+		for _, name := range names {
+			if _, exists := repoByName[name]; !exists {
+				repoByName[name] = repo
+			}
+		}
+	}
+
+	return repoByName, nil
+}
+
+// repo sections from pacman.conf, falling back to the sync databases on disk
+func getRepoOrder() ([]string, error) {
+	file, err := os.Open(pacmanConfPath)
+	if err == nil {
+		defer file.Close()
+
+		var repos []string
+		scanner := bufio.NewScanner(file)
+
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") && line != "[options]" {
+				repos = append(repos, line[1:len(line)-1])
+			}
+		}
+
+		if err = scanner.Err(); err == nil && len(repos) > 0 {
+			return repos, nil
+		}
+	}
+
+	entries, err := os.ReadDir(PacmanSyncDbPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sync databases: %v", err)
+	}
+
+	var repos []string
+	for _, entry := range entries {
+		if repo, isDb := strings.CutSuffix(entry.Name(), syncDbExt); isDb && !entry.IsDir() {
+			repos = append(repos, repo)
+		}
+	}
+
+	return repos, nil
+}
+
+// sync databases are gzipped tarballs with one name-version-release/ directory per package
+func readSyncDbNames(dbPath string) ([]string, error) {
+	file, err := os.Open(dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sync database %s: %v", filepath.Base(dbPath), err)
+	}
+
+	defer gzipReader.Close()
+
+	var names []string
+	seen := make(map[string]bool)
+	tarReader := tar.NewReader(gzipReader)
+
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to read sync database %s: %v", filepath.Base(dbPath), err)
+		}
+
+		pkgDir, _, _ := strings.Cut(header.Name, "/")
+		if seen[pkgDir] {
+			continue
+		}
+
+		seen[pkgDir] = true
+
+		if name, ok := trimVersionRelease(pkgDir); ok {
+			names = append(names, name)
+		}
+	}
+
+	return names, nil
+}
+
+// package names can contain dashes, versions and releases cannot
+func trimVersionRelease(pkgDir string) (string, bool) {
+	releaseIdx := strings.LastIndex(pkgDir, "-")
+	if releaseIdx <= 0 {
+		return "", false
+	}
+
+	versionIdx := strings.LastIndex(pkgDir[:releaseIdx], "-")
+	if versionIdx <= 0 {
+		return "", false
+	}
+
+	return pkgDir[:versionIdx], true
+}
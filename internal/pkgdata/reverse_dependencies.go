@@ -1,6 +1,7 @@
 package pkgdata
 
 import (
+	"sort"
 	"yaylog/internal/pipeline/meta"
 )
 
@@ -9,41 +10,161 @@ func CalculateReverseDependencies(
 	pkgPtrs []*PkgInfo,
 	_ meta.ProgressReporter, // TODO: Add progress reporting
 ) ([]*PkgInfo, error) {
-	packagePointerMap := make(map[string]*PkgInfo)
-	packageDependencyMap := make(map[string][]Relation)
-	providesMap := make(map[string]string)
-	// key: provided library/package, value: package that provides it (provider)
+	// dependencies resolve the same way as for transitive queries, see pkgResolver.resolve
+	resolver := newPkgResolver(pkgPtrs)
+	packageDependencyMap := make(map[*PkgInfo][]Relation)
 
 	for _, pkg := range pkgPtrs {
-		packagePointerMap[pkg.Name] = pkg
+		pkg.RequiredBy = nil // cached packages can hold outdated reverse dependencies
+	}
+
+	for _, pkg := range pkgPtrs {
+		for _, depPackage := range pkg.Depends {
+			dep, exists := resolver.resolve(depPackage.Name)
+			if !exists || dep == pkg {
+				continue // skip if a package names itself as a dependency
+			}
 
-		// populate providesMap
-		for _, provided := range pkg.Provides {
-			providesMap[provided.Name] = pkg.Name
+			packageDependencyMap[dep] = append(packageDependencyMap[dep], Relation{Name: pkg.Name})
 		}
 	}
 
+	for pkg, requiredBy := range packageDependencyMap {
+		pkg.RequiredBy = requiredBy
+		sortRelationsByName(pkg.RequiredBy)
+	}
+
+	return pkgPtrs, nil
+}
+
+// updates RequiredBy after a fetch that reused cached packages, only touching the packages
+// that changed packages depend on. cached packages must already have their reverse dependencies.
+// returns false when provides changed or a new or removed package changes what dependencies of
+// unchanged packages resolve to, as those then need a full CalculateReverseDependencies
+func UpdateReverseDependencies(pkgPtrs []*PkgInfo, delta *PkgDelta) bool {
+	resolver := newPkgResolver(pkgPtrs)
+
+	freshSet := make(map[*PkgInfo]bool, len(delta.Fresh))
+	for _, fresh := range delta.Fresh {
+		freshSet[fresh] = true
+	}
+
+	// what unchanged packages depend on, they never add themselves to the RequiredBy of new packages
+	reusedDepNames := make(map[string]bool)
 	for _, pkg := range pkgPtrs {
-		for _, depPackage := range pkg.Depends {
-			depName := depPackage.Name
+		if freshSet[pkg] {
+			continue
+		}
+
+		for _, dep := range pkg.Depends {
+			reusedDepNames[dep.Name] = true
+		}
+	}
+
+	staleMap := make(map[string]*PkgInfo, len(delta.Stale))
+	for _, stale := range delta.Stale {
+		staleMap[stale.Name] = stale
+
+		for _, provided := range stale.Provides {
+			if provider, exists := resolver.providers[provided.Name]; exists && provider.Name != stale.Name {
+				return false
+			}
+		}
+
+		// dependencies on a removed package fall through to whatever provides its name
+		if _, isUpgrade := resolver.pkgsByName[stale.Name]; !isUpgrade && reusedDepNames[stale.Name] {
+			if _, isProvided := resolver.providers[stale.Name]; isProvided {
+				return false
+			}
+		}
+	}
+
+	for _, fresh := range delta.Fresh {
+		stale, isUpgrade := staleMap[fresh.Name]
+		if isUpgrade && !equalRelationNames(stale.Provides, fresh.Provides) {
+			return false
+		}
 
-			if providerName, exists := providesMap[depName]; exists {
-				depName = providerName
+		for _, provided := range fresh.Provides {
+			if resolver.providers[provided.Name] != fresh {
+				return false // provided by more than one package
 			}
 
-			if depName == pkg.Name {
+			if !isUpgrade && reusedDepNames[provided.Name] {
+				return false
+			}
+		}
+
+		// an upgrade keeps the dependents of its earlier version, a new package has none yet
+		if !isUpgrade && reusedDepNames[fresh.Name] {
+			return false
+		}
+	}
+
+	// dependents of an upgraded package are unchanged, minus the ones that changed themselves
+	for _, fresh := range delta.Fresh {
+		if stale, isUpgrade := staleMap[fresh.Name]; isUpgrade {
+			fresh.RequiredBy = stale.RequiredBy
+		}
+	}
+
+	for _, stale := range delta.Stale {
+		for _, dep := range stale.Depends {
+			if target, exists := resolver.resolve(dep.Name); exists {
+				target.RequiredBy = removeRelation(target.RequiredBy, stale.Name)
+			}
+		}
+	}
+
+	touched := make(map[*PkgInfo]bool)
+
+	for _, fresh := range delta.Fresh {
+		for _, dep := range fresh.Depends {
+			target, exists := resolver.resolve(dep.Name)
+			if !exists || target == fresh {
 				continue // skip if a package names itself as a dependency
 			}
 
-			packageDependencyMap[depName] = append(packageDependencyMap[depName], Relation{Name: pkg.Name})
+			target.RequiredBy = append(target.RequiredBy, Relation{Name: fresh.Name})
+			touched[target] = true
 		}
 	}
 
-	for name, requiredBy := range packageDependencyMap {
-		if pkg, exists := packagePointerMap[name]; exists {
-			pkg.RequiredBy = requiredBy
+	// appended names would otherwise always trail, depending on what was upgraded last
+	for target := range touched {
+		sortRelationsByName(target.RequiredBy)
+	}
+
+	return true
+}
+
+func sortRelationsByName(rels []Relation) {
+	sort.SliceStable(rels, func(i int, j int) bool {
+		return rels[i].Name < rels[j].Name
+	})
+}
+
+func removeRelation(rels []Relation, name string) []Relation {
+	kept := make([]Relation, 0, len(rels))
+	for _, rel := range rels {
+		if rel.Name != name {
+			kept = append(kept, rel)
 		}
 	}
 
-	return pkgPtrs, nil
+	return kept
+}
+
+func equalRelationNames(a []Relation, b []Relation) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i].Name != b[i].Name {
+			return false
+		}
+	}
+
+	return true
 }
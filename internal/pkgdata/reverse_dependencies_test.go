@@ -0,0 +1,179 @@
+package pkgdata
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestUpdateReverseDependencies(t *testing.T) {
+	type pkgSpec struct {
+		name     string
+		version  string
+		depends  string
+		provides string
+	}
+
+	relations := func(names string) []Relation {
+		var rels []Relation
+		for _, name := range strings.Fields(names) {
+			rels = append(rels, Relation{Name: name})
+		}
+
+		return rels
+	}
+
+	build := func(spec pkgSpec) *PkgInfo {
+		return &PkgInfo{
+			Name:     spec.name,
+			Version:  spec.version,
+			Depends:  relations(spec.depends),
+			Provides: relations(spec.provides),
+		}
+	}
+
+	requiredBy := func(pkgPtrs []*PkgInfo) map[string][]string {
+		result := make(map[string][]string, len(pkgPtrs))
+		for _, pkg := range pkgPtrs {
+			for _, rel := range pkg.RequiredBy {
+				result[pkg.Name] = append(result[pkg.Name], rel.Name)
+			}
+		}
+
+		return result
+	}
+
+	cases := []struct {
+		description string
+		before      []pkgSpec
+		after       []pkgSpec
+		incremental bool
+	}{
+		{
+			"add",
+			[]pkgSpec{{"app", "1", "lib", ""}, {"lib", "1", "", ""}},
+			[]pkgSpec{{"app", "1", "lib", ""}, {"lib", "1", "", ""}, {"tool", "1", "lib app", ""}},
+			true,
+		},
+		{
+			"remove",
+			[]pkgSpec{{"app", "1", "lib", ""}, {"tool", "1", "lib", ""}, {"lib", "1", "", ""}},
+			[]pkgSpec{{"app", "1", "lib", ""}, {"lib", "1", "", ""}},
+			true,
+		},
+		{
+			"upgrade",
+			[]pkgSpec{{"app", "1", "lib", ""}, {"lib", "1", "", "lib.so"}, {"glibc", "1", "", ""}, {"zed", "1", "lib.so", ""}},
+			[]pkgSpec{{"app", "2", "glibc", ""}, {"lib", "2", "glibc", "lib.so"}, {"glibc", "1", "", ""}, {"zed", "1", "lib.so", ""}},
+			true,
+		},
+		{
+			"new provider",
+			[]pkgSpec{{"app", "1", "libfoo.so", ""}},
+			[]pkgSpec{{"app", "1", "libfoo.so", ""}, {"foo", "1", "", "libfoo.so"}},
+			false,
+		},
+		{
+			"removed package still provided by another",
+			[]pkgSpec{{"app", "1", "foo", ""}, {"foo", "1", "", ""}, {"foo-git", "1", "", "foo"}},
+			[]pkgSpec{{"app", "1", "foo", ""}, {"foo-git", "1", "", "foo"}},
+			false,
+		},
+		{
+			"new package for a missing dependency",
+			[]pkgSpec{{"app", "1", "lib", ""}},
+			[]pkgSpec{{"app", "1", "lib", ""}, {"lib", "1", "", ""}},
+			false,
+		},
+	}
+
+	for _, c := range cases {
+		var cachedPkgs []*PkgInfo
+		cachedMap := make(map[string]*PkgInfo)
+		for _, spec := range c.before {
+			pkg := build(spec)
+			cachedPkgs = append(cachedPkgs, pkg)
+			cachedMap[spec.name] = pkg
+		}
+
+		CalculateReverseDependencies(cachedPkgs, nil)
+
+		// unchanged packages are reused from the cache, like FetchPackages does
+		delta := &PkgDelta{}
+		var pkgPtrs, expectedPkgs []*PkgInfo
+		kept := make(map[string]bool)
+
+		for _, spec := range c.after {
+			expectedPkgs = append(expectedPkgs, build(spec))
+
+			cached, exists := cachedMap[spec.name]
+			if exists && cached.Version == spec.version {
+				pkgPtrs = append(pkgPtrs, cached)
+				kept[spec.name] = true
+				delta.Reused++
+				continue
+			}
+
+			fresh := build(spec)
+			pkgPtrs = append(pkgPtrs, fresh)
+			delta.Fresh = append(delta.Fresh, fresh)
+		}
+
+		for _, cached := range cachedPkgs {
+			if !kept[cached.Name] {
+				delta.Stale = append(delta.Stale, cached)
+			}
+		}
+
+		incremental := UpdateReverseDependencies(pkgPtrs, delta)
+		if incremental != c.incremental {
+			t.Errorf("%s: expected incremental update %v, got %v", c.description, c.incremental, incremental)
+		}
+
+		if !incremental {
+			CalculateReverseDependencies(pkgPtrs, nil)
+		}
+
+		CalculateReverseDependencies(expectedPkgs, nil)
+
+		expected, got := requiredBy(expectedPkgs), requiredBy(pkgPtrs)
+		for name := range expected {
+			if !slices.Equal(expected[name], got[name]) {
+				t.Errorf("%s: expected %s to be required by %v, got %v", c.description, name, expected[name], got[name])
+			}
+		}
+
+		for name := range got {
+			if _, exists := expected[name]; !exists {
+				t.Errorf("%s: expected %s to be required by nothing, got %v", c.description, name, got[name])
+			}
+		}
+	}
+}
+
+func TestReverseDependenciesMatchTransitive(t *testing.T) {
+	pkgs := []*PkgInfo{
+		{Name: "app", Depends: []Relation{{Name: "jack"}, {Name: "libjpeg"}}},
+		{Name: "jack"},
+		{Name: "pipewire-jack", Provides: []Relation{{Name: "jack"}}},
+		{Name: "libjpeg-turbo", Provides: []Relation{{Name: "libjpeg"}}},
+	}
+
+	CalculateReverseDependencies(pkgs, nil)
+
+	// required-by and required-by*1 resolve dependencies the same way
+	for _, pkg := range pkgs {
+		var requiredBy, dependents []string
+		for _, rel := range pkg.RequiredBy {
+			requiredBy = append(requiredBy, rel.Name)
+		}
+
+		for _, dependent := range TransitiveDependents(pkgs, []string{pkg.Name}, 1) {
+			dependents = append(dependents, dependent.Name)
+		}
+
+		if !slices.Equal(requiredBy, dependents) {
+			t.Errorf("%s: required by %v, but direct dependents are %v", pkg.Name, requiredBy, dependents)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package pkgdata
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// what a search term scores depending on where it matched, name matches outrank description matches
+const (
+	scoreNameExact       = 100
+	scoreNamePrefix      = 40
+	scoreNameWord        = 30
+	scoreNameSubstring   = 20
+	scoreDescriptionWord = 10
+	scoreDescription     = 5
+)
+
+// splits text into words, on anything that is not a letter or digit
+func tokenizeSearchText(text string) []string {
+	return strings.FieldsFunc(text, func(char rune) bool {
+		return !unicode.IsLetter(char) && !unicode.IsDigit(char)
+	})
+}
+
+// keeps packages whose name or description contains every term, like pacman -Qs,
+// and sets their relevance from where the terms matched
+func SearchPackages(pkgPtrs []*PkgInfo, terms []string, caseSensitive bool) []*PkgInfo {
+	normalize := func(value string) string { return value }
+	if !caseSensitive {
+		normalize = strings.ToLower
+	}
+
+	normalizedTerms := make([]string, len(terms))
+	for i, term := range terms {
+		normalizedTerms[i] = normalize(term)
+	}
+
+	var matchedPkgs []*PkgInfo
+
+	for _, pkg := range pkgPtrs {
+		name := normalize(pkg.Name)
+		description := normalize(pkg.Description)
+		nameWords := tokenizeSearchText(name)
+		descriptionWords := tokenizeSearchText(description)
+
+		relevance := 0
+
+		for _, term := range normalizedTerms {
+			termScore := scoreSearchTerm(term, name, nameWords, description, descriptionWords)
+			if termScore == 0 {
+				relevance = 0
+				break
+			}
+
+			relevance += termScore
+		}
+
+		if relevance > 0 {
+			pkg.Relevance = relevance
+			matchedPkgs = append(matchedPkgs, pkg)
+		}
+	}
+
+	return matchedPkgs
+}
+
+// the best place the term matched, 0 if it matched nowhere
+func scoreSearchTerm(
+	term string,
+	name string,
+	nameWords []string,
+	description string,
+	descriptionWords []string,
+) int {
+	switch {
+	case name == term:
+		return scoreNameExact
+	case strings.HasPrefix(name, term):
+		return scoreNamePrefix
+	case slices.Contains(nameWords, term):
+		return scoreNameWord
+	case strings.Contains(name, term):
+		return scoreNameSubstring
+	case slices.Contains(descriptionWords, term):
+		return scoreDescriptionWord
+	case strings.Contains(description, term):
+		return scoreDescription
+	default:
+		return 0
+	}
+}
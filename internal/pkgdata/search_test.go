@@ -0,0 +1,49 @@
+package pkgdata
+
+import "testing"
+
+func TestSearchPackages(t *testing.T) {
+	pkgs := []*PkgInfo{
+		{Name: "go", Description: "Core compiler tools for the Go programming language"},
+		{Name: "mongodb-bin", Description: "A document-oriented database"},
+		{Name: "python", Description: "The Python programming language"},
+		{Name: "go-tools", Description: "Developer tools for Go"},
+	}
+
+	cases := []struct {
+		terms    []string
+		expected map[string]int
+	}{
+		{[]string{"go"}, map[string]int{"go": 100, "go-tools": 40, "mongodb-bin": 20, "python": 0}},
+		{[]string{"GO", "language"}, map[string]int{"go": 110, "python": 0}},
+		{[]string{"tools", "developer"}, map[string]int{"go-tools": 40}},
+		{[]string{"missing"}, map[string]int{}},
+	}
+
+	for _, c := range cases {
+		for _, pkg := range pkgs {
+			pkg.Relevance = 0
+		}
+
+		result := SearchPackages(pkgs, c.terms, false)
+
+		matched := make(map[string]int, len(result))
+		for _, pkg := range result {
+			matched[pkg.Name] = pkg.Relevance
+		}
+
+		for name, relevance := range c.expected {
+			if relevance == 0 {
+				if _, exists := matched[name]; exists {
+					t.Errorf("SearchPackages(%q): expected %s not to match", c.terms, name)
+				}
+
+				continue
+			}
+
+			if matched[name] != relevance {
+				t.Errorf("SearchPackages(%q): expected %s to score %d, got %d", c.terms, name, relevance, matched[name])
+			}
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package pkgdata
+
+import (
+	"fmt"
+	"sort"
+	"yaylog/internal/pacmanlog"
+	"yaylog/internal/pipeline/meta"
+)
+
+// packages installed further apart than this, without a known shared transaction, are separate sessions
+const sessionGapSeconds = 60
+
+type Session struct {
+	Id        int
+	Timestamp int64 // earliest install in the session
+	Pkgs      []*PkgInfo
+}
+
+// numbers install sessions chronologically, starting at 1.
+// pacman.log transactions are used when events are given, install time clustering otherwise
+func AssignSessions(
+	pkgPtrs []*PkgInfo,
+	events []pacmanlog.Event,
+	reportProgress meta.ProgressReporter,
+) []*PkgInfo {
+	lastEvents := make(map[string]pacmanlog.Event)
+	for _, event := range events {
+		if event.Action != pacmanlog.ActionRemoved {
+			lastEvents[event.Name] = event // the last event is the one that set the install date
+		}
+	}
+
+	// the log and the database can disagree, e.g. after a rotated log or a restored database
+	transactions := make(map[string]int, len(lastEvents))
+	for _, pkg := range pkgPtrs {
+		event, exists := lastEvents[pkg.Name]
+		if exists && abs(event.Timestamp-pkg.Timestamp) <= sessionGapSeconds {
+			transactions[pkg.Name] = event.Transaction
+		}
+	}
+
+	sortedPkgs := make([]*PkgInfo, len(pkgPtrs))
+	copy(sortedPkgs, pkgPtrs)
+	sort.SliceStable(sortedPkgs, func(i int, j int) bool {
+		return sortedPkgs[i].Timestamp < sortedPkgs[j].Timestamp
+	})
+
+	sessionId := 0
+	var prev *PkgInfo
+
+	for _, pkg := range sortedPkgs {
+		if prev == nil || isNewSession(prev, pkg, transactions) {
+			sessionId++
+		}
+
+		pkg.Session = sessionId
+		prev = pkg
+	}
+
+	if reportProgress != nil {
+		reportProgress(100, 100, fmt.Sprintf("Found %d install sessions", sessionId))
+	}
+
+	return pkgPtrs
+}
+
+func isNewSession(prev *PkgInfo, pkg *PkgInfo, transactions map[string]int) bool {
+	prevTransaction := transactions[prev.Name]
+	transaction := transactions[pkg.Name]
+
+	if prevTransaction != 0 && transaction != 0 {
+		return prevTransaction != transaction
+	}
+
+	return pkg.Timestamp-prev.Timestamp > sessionGapSeconds
+}
+
+func abs(value int64) int64 {
+	if value < 0 {
+		return -value
+	}
+
+	return value
+}
+
+// groups packages by session in chronological order, keeping the package order within each session
+func GroupSessions(pkgPtrs []*PkgInfo) []*Session {
+	sessionsById := make(map[int]*Session)
+	var sessions []*Session
+
+	for _, pkg := range pkgPtrs {
+		session, exists := sessionsById[pkg.Session]
+		if !exists {
+			session = &Session{Id: pkg.Session, Timestamp: pkg.Timestamp}
+			sessionsById[pkg.Session] = session
+			sessions = append(sessions, session)
+		}
+
+		session.Timestamp = min(session.Timestamp, pkg.Timestamp)
+		session.Pkgs = append(session.Pkgs, pkg)
+	}
+
+	sort.Slice(sessions, func(i int, j int) bool {
+		return sessions[i].Id < sessions[j].Id
+	})
+
+	return sessions
+}
+
+// keeps the packages of the most recent sessions, in their current order
+func LatestSessions(pkgPtrs []*PkgInfo, count int) []*PkgInfo {
+	sessionIds := make(map[int]bool)
+	for _, pkg := range pkgPtrs {
+		sessionIds[pkg.Session] = true
+	}
+
+	if len(sessionIds) <= count {
+		return pkgPtrs
+	}
+
+	sortedIds := make([]int, 0, len(sessionIds))
+	for id := range sessionIds {
+		sortedIds = append(sortedIds, id)
+	}
+
+	sort.Ints(sortedIds)
+	minId := sortedIds[len(sortedIds)-count]
+
+	latestPkgs := make([]*PkgInfo, 0, len(pkgPtrs))
+	for _, pkg := range pkgPtrs {
+		if pkg.Session >= minId {
+			latestPkgs = append(latestPkgs, pkg)
+		}
+	}
+
+	return latestPkgs
+}
@@ -0,0 +1,215 @@
+package pkgdata
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	pb "yaylog/internal/protobuf"
+	"yaylog/internal/timeexpr"
+
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	snapshotExt = ".snapshot"
+
+	SnapshotRefLatest  = "last"
+	SnapshotRefCurrent = "current" // the installed packages, resolved by the caller
+)
+
+// snapshots are numbered chronologically starting at 1, the number is not stored
+type Snapshot struct {
+	Id        int
+	Timestamp int64
+	Pkgs      []*PkgInfo // only name, version, reason, size and repo are recorded
+}
+
+func getSnapshotDir() (string, error) {
+	cacheDir, err := getCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(cacheDir, "snapshots"), nil
+}
+
+// records the package set unless it is identical to the latest snapshot.
+// returns the latest snapshot and whether it was newly recorded
+func SaveSnapshot(pkgPtrs []*PkgInfo, timestamp int64) (*Snapshot, bool, error) {
+	snapshotDir, err := getSnapshotDir()
+	if err != nil {
+		return nil, false, err
+	}
+
+	// held across the comparison too, so concurrent runs cannot both record the same change
+	unlock, err := lockFile(snapshotDir, true)
+	if err != nil {
+		return nil, false, err
+	}
+
+	defer unlock()
+
+	snapshots, err := LoadSnapshots()
+	if err != nil {
+		return nil, false, err
+	}
+
+	if len(snapshots) > 0 {
+		latest := snapshots[len(snapshots)-1]
+		if len(DiffPackages(latest.Pkgs, pkgPtrs)) == 0 {
+			return latest, false, nil
+		}
+	}
+
+	// the repo only adds detail to trends, an unreadable sync database leaves it unknown
+	_ = AssignRepos(pkgPtrs)
+
+	byteData, err := proto.Marshal(&pb.Snapshot{
+		Timestamp: timestamp,
+		Pkgs:      pkgsToSnapshotProtos(pkgPtrs),
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to marshal snapshot: %v", err)
+	}
+
+	snapshotPath := filepath.Join(snapshotDir, strconv.FormatInt(timestamp, 10)+snapshotExt)
+	if err = writeFileAtomic(snapshotPath, byteData); err != nil {
+		return nil, false, err
+	}
+
+	snapshot := &Snapshot{
+		Id:        len(snapshots) + 1,
+		Timestamp: timestamp,
+		Pkgs:      pkgPtrs,
+	}
+
+	return snapshot, true, nil
+}
+
+// loads every recorded snapshot, oldest first. no snapshot dir means no snapshots
+func LoadSnapshots() ([]*Snapshot, error) {
+	snapshotDir, err := getSnapshotDir()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(snapshotDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot dir: %v", err)
+	}
+
+	var snapshots []*Snapshot
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), snapshotExt) {
+			continue
+		}
+
+		snapshot, err := loadSnapshot(filepath.Join(snapshotDir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		snapshots = append(snapshots, snapshot)
+	}
+
+	sort.SliceStable(snapshots, func(i int, j int) bool {
+		return snapshots[i].Timestamp < snapshots[j].Timestamp
+	})
+
+	for i, snapshot := range snapshots {
+		snapshot.Id = i + 1
+	}
+
+	return snapshots, nil
+}
+
+func loadSnapshot(snapshotPath string) (*Snapshot, error) {
+	byteData, err := os.ReadFile(snapshotPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %v", err)
+	}
+
+	var pbSnapshot pb.Snapshot
+	if err = proto.Unmarshal(byteData, &pbSnapshot); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal snapshot %s: %v", filepath.Base(snapshotPath), err)
+	}
+
+	return &Snapshot{
+		Timestamp: pbSnapshot.Timestamp,
+		Pkgs:      snapshotProtosToPkgs(pbSnapshot.Pkgs),
+	}, nil
+}
+
+// a reference is a snapshot number, "last", or a date selecting the latest snapshot taken up to then
+func ResolveSnapshot(snapshots []*Snapshot, ref string, location *time.Location) (*Snapshot, error) {
+	if len(snapshots) == 0 {
+		return nil, fmt.Errorf("no snapshots recorded yet, run yaylog snapshot first")
+	}
+
+	if ref == SnapshotRefLatest {
+		return snapshots[len(snapshots)-1], nil
+	}
+
+	if id, err := strconv.Atoi(ref); err == nil {
+		if id < 1 || id > len(snapshots) {
+			return nil, fmt.Errorf("snapshot %d does not exist, there are %d snapshots", id, len(snapshots))
+		}
+
+		return snapshots[id-1], nil
+	}
+
+	span, err := timeexpr.Parse(ref, time.Now(), location)
+	if err != nil {
+		return nil, fmt.Errorf("invalid snapshot reference: %q", ref)
+	}
+
+	until := span.Start.Unix()
+	idx := sort.Search(len(snapshots), func(i int) bool {
+		return snapshots[i].Timestamp > until
+	})
+
+	if idx == 0 {
+		return nil, fmt.Errorf("no snapshot was recorded before %s", ref)
+	}
+
+	return snapshots[idx-1], nil
+}
+
+func pkgsToSnapshotProtos(pkgPtrs []*PkgInfo) []*pb.SnapshotPkg {
+	pbPkgs := make([]*pb.SnapshotPkg, len(pkgPtrs))
+	for i, pkg := range pkgPtrs {
+		pbPkgs[i] = &pb.SnapshotPkg{
+			Name:    pkg.Name,
+			Version: pkg.Version,
+			Reason:  pkg.Reason,
+			Size:    pkg.Size,
+			Repo:    pkg.Repo,
+		}
+	}
+
+	return pbPkgs
+}
+
+func snapshotProtosToPkgs(pbPkgs []*pb.SnapshotPkg) []*PkgInfo {
+	pkgPtrs := make([]*PkgInfo, len(pbPkgs))
+	for i, pbPkg := range pbPkgs {
+		pkgPtrs[i] = &PkgInfo{
+			Name:    pbPkg.Name,
+			Version: pbPkg.Version,
+			Reason:  pbPkg.Reason,
+			Size:    pbPkg.Size,
+			Repo:    pbPkg.Repo,
+		}
+	}
+
+	return pkgPtrs
+}
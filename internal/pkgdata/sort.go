@@ -46,6 +46,37 @@ func GetComparator(field consts.FieldType, asc bool) PkgComparator {
 	case consts.FieldLicense:
 		return makeComparator(func(p *PkgInfo) string { return strings.ToLower(p.License) }, asc)
 
+	case consts.FieldExclusiveDeps:
+		return makeComparator(func(p *PkgInfo) int64 { return int64(p.ExclusiveDeps) }, asc)
+
+	case consts.FieldSharedDeps:
+		return makeComparator(func(p *PkgInfo) int64 { return int64(p.SharedDeps) }, asc)
+
+	case consts.FieldExclusiveSize:
+		return makeComparator(func(p *PkgInfo) int64 { return p.ExclusiveSize }, asc)
+
+	case consts.FieldSharedSize:
+		return makeComparator(func(p *PkgInfo) int64 { return p.SharedSize }, asc)
+
+	case consts.FieldFirstInstalled:
+		return makeComparator(func(p *PkgInfo) int64 { return p.FirstInstalled }, asc)
+
+	case consts.FieldLastUpgrade:
+		return makeComparator(func(p *PkgInfo) int64 { return p.LastUpgrade }, asc)
+
+	case consts.FieldUpgradeCount:
+		return makeComparator(func(p *PkgInfo) int64 { return int64(p.UpgradeCount) }, asc)
+
+	case consts.FieldRelevance:
+		// equally relevant packages keep a stable order by name
+		return func(a, b *PkgInfo) bool {
+			if a.Relevance != b.Relevance {
+				return (a.Relevance < b.Relevance) == asc
+			}
+
+			return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+		}
+
 	default:
 		return nil
 	}
@@ -0,0 +1,128 @@
+package pkgdata
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type MatchMode int
+
+const (
+	MatchSubstring MatchMode = iota
+	MatchExact
+	MatchGlob
+	MatchRegex
+	MatchFuzzy
+)
+
+// reports whether a value matches any of the targets it was built from
+type StringMatcher func(value string) bool
+
+// patterns are compiled once here, not for every package.
+// regex mode takes a single pattern, the other modes any number of targets
+func NewStringMatcher(mode MatchMode, targets []string, caseSensitive bool) (StringMatcher, error) {
+	if mode == MatchRegex {
+		return newRegexMatcher(targets, caseSensitive)
+	}
+
+	normalize := func(value string) string { return value }
+	if !caseSensitive {
+		normalize = strings.ToLower
+	}
+
+	normalized := make([]string, len(targets))
+	for i, target := range targets {
+		normalized[i] = normalize(target)
+	}
+
+	var matchesTarget func(value string, target string) bool
+
+	switch mode {
+	case MatchExact:
+		matchesTarget = func(value string, target string) bool { return value == target }
+	case MatchGlob:
+		globs := make(map[string]*regexp.Regexp, len(normalized))
+		for _, target := range normalized {
+			re, err := compileGlob(target)
+			if err != nil {
+				return nil, fmt.Errorf("invalid glob pattern %q: %v", target, err)
+			}
+
+			globs[target] = re
+		}
+
+		matchesTarget = func(value string, target string) bool { return globs[target].MatchString(value) }
+	case MatchFuzzy:
+		matchesTarget = func(value string, target string) bool { return IsFuzzyMatch(target, value) }
+	default:
+		matchesTarget = strings.Contains
+	}
+
+	return func(value string) bool {
+		value = normalize(value)
+
+		for _, target := range normalized {
+			if matchesTarget(value, target) {
+				return true
+			}
+		}
+
+		return false
+	}, nil
+}
+
+func newRegexMatcher(targets []string, caseSensitive bool) (StringMatcher, error) {
+	pattern := strings.Join(targets, ",")
+	if !caseSensitive {
+		pattern = "(?i)" + pattern
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regular expression %q: %v", strings.Join(targets, ","), err)
+	}
+
+	return re.MatchString, nil
+}
+
+// a glob matches the whole value, * and ? match any character including /,
+// so that patterns such as *github.com* work on urls and descriptions
+func compileGlob(pattern string) (*regexp.Regexp, error) {
+	var builder strings.Builder
+	builder.WriteString("^")
+
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '*':
+			builder.WriteString(".*")
+		case '?':
+			builder.WriteString(".")
+		case '\\':
+			if i+1 == len(pattern) {
+				return nil, fmt.Errorf("trailing backslash")
+			}
+
+			i++
+			builder.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated character class")
+			}
+
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:] // shell style negation
+			}
+
+			builder.WriteString("[" + strings.ReplaceAll(class, "\\", "\\\\") + "]")
+			i += end + 1
+		default:
+			builder.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+
+	builder.WriteString("$")
+	return regexp.Compile(builder.String())
+}
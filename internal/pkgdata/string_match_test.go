@@ -0,0 +1,59 @@
+package pkgdata
+
+import "testing"
+
+func TestNewStringMatcher(t *testing.T) {
+	cases := []struct {
+		mode          MatchMode
+		targets       []string
+		caseSensitive bool
+		value         string
+		expected      bool
+	}{
+		{MatchExact, []string{"vlc"}, false, "vlc", true},
+		{MatchExact, []string{"vlc"}, false, "libvlc", false},
+		{MatchExact, []string{"VLC"}, false, "vlc", true},
+		{MatchSubstring, []string{"vlc"}, false, "libvlccore", true},
+		{MatchSubstring, []string{"mpv", "vlc"}, false, "libvlc", true},
+		{MatchSubstring, []string{"ffmpeg"}, false, "libvlc", false},
+		{MatchGlob, []string{"*github.com*"}, false, "https://github.com/zweih/yaylog", true},
+		{MatchGlob, []string{"https://*/yaylog"}, false, "https://github.com/zweih/yaylog", true},
+		{MatchGlob, []string{"*gitlab.com*"}, false, "https://github.com/zweih/yaylog", false},
+		{MatchGlob, []string{"lib*"}, false, "glibc", false},
+		{MatchGlob, []string{"python-?ip"}, false, "python-pip", true},
+		{MatchGlob, []string{"qt[56]-base"}, false, "qt6-base", true},
+		{MatchGlob, []string{"qt[!56]-base"}, false, "qt6-base", false},
+		{MatchGlob, []string{"lib.*"}, false, "libjpeg", false},
+		{MatchRegex, []string{"^lib.*core$"}, false, "libvlccore", true},
+		{MatchRegex, []string{"^LIB"}, false, "libvlc", true},
+		{MatchRegex, []string{"^LIB"}, true, "libvlc", false},
+		{MatchRegex, []string{"^lib"}, true, "libvlc", true},
+	}
+
+	for _, c := range cases {
+		matcher, err := NewStringMatcher(c.mode, c.targets, c.caseSensitive)
+		if err != nil {
+			t.Errorf("NewStringMatcher(%d, %q): unexpected error: %v", c.mode, c.targets, err)
+			continue
+		}
+
+		if result := matcher(c.value); result != c.expected {
+			t.Errorf("NewStringMatcher(%d, %q)(%q): expected %v, got %v", c.mode, c.targets, c.value, c.expected, result)
+		}
+	}
+
+	invalid := []struct {
+		mode    MatchMode
+		targets []string
+	}{
+		{MatchGlob, []string{"qt[56-base"}},
+		{MatchGlob, []string{"vlc\\"}},
+		{MatchRegex, []string{"lib("}},
+	}
+
+	for _, c := range invalid {
+		if _, err := NewStringMatcher(c.mode, c.targets, false); err == nil {
+			t.Errorf("NewStringMatcher(%d, %q): expected an error", c.mode, c.targets)
+		}
+	}
+}
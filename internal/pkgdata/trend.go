@@ -0,0 +1,86 @@
+package pkgdata
+
+import (
+	"fmt"
+	"time"
+	"yaylog/internal/consts"
+)
+
+const RepoUnknown = "unknown" // recorded before repos were, or without sync databases
+
+type TrendTotals struct {
+	PkgCount int
+	Size     int64
+}
+
+// one point per period that has snapshots, taken from the last snapshot in it
+type TrendPoint struct {
+	Period   string
+	Snapshot *Snapshot
+	Total    TrendTotals
+	ByReason map[string]TrendTotals
+	ByRepo   map[string]TrendTotals
+}
+
+// snapshots are expected oldest first, as returned by LoadSnapshots
+func BuildTrend(snapshots []*Snapshot, period string, location *time.Location) []TrendPoint {
+	var points []TrendPoint
+
+	for _, snapshot := range snapshots {
+		label := periodLabel(snapshot.Timestamp, period, location)
+		point := summarizeSnapshot(snapshot, label)
+
+		if len(points) > 0 && points[len(points)-1].Period == label {
+			points[len(points)-1] = point
+			continue
+		}
+
+		points = append(points, point)
+	}
+
+	return points
+}
+
+func summarizeSnapshot(snapshot *Snapshot, label string) TrendPoint {
+	point := TrendPoint{
+		Period:   label,
+		Snapshot: snapshot,
+		ByReason: make(map[string]TrendTotals),
+		ByRepo:   make(map[string]TrendTotals),
+	}
+
+	for _, pkg := range snapshot.Pkgs {
+		repo := pkg.Repo
+		if repo == "" {
+			repo = RepoUnknown
+		}
+
+		point.Total = point.Total.add(pkg)
+		point.ByReason[pkg.Reason] = point.ByReason[pkg.Reason].add(pkg)
+		point.ByRepo[repo] = point.ByRepo[repo].add(pkg)
+	}
+
+	return point
+}
+
+func (totals TrendTotals) add(pkg *PkgInfo) TrendTotals {
+	return TrendTotals{
+		PkgCount: totals.PkgCount + 1,
+		Size:     totals.Size + pkg.Size,
+	}
+}
+
+// periods follow the calendar of location, weeks follow ISO 8601
+func periodLabel(timestamp int64, period string, location *time.Location) string {
+	date := time.Unix(timestamp, 0).In(location)
+
+	switch period {
+	case consts.TrendDay:
+		return date.Format(consts.DateOnlyFormat)
+	case consts.TrendWeek:
+		year, week := date.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	default:
+		return date.Format("2006-01")
+	}
+}
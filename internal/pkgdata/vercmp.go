@@ -0,0 +1,163 @@
+package pkgdata
+
+import "strings"
+
+// compares [epoch:]version[-release] strings the way pacman does (alpm_pkg_vercmp).
+// returns -1 if a is older than b, 0 if they are equal, 1 if a is newer
+func CompareVersions(a string, b string) int {
+	if a == b {
+		return 0
+	}
+
+	epochA, versionA, releaseA := splitVersion(a)
+	epochB, versionB, releaseB := splitVersion(b)
+
+	if result := compareSegments(epochA, epochB); result != 0 {
+		return result
+	}
+
+	if result := compareSegments(versionA, versionB); result != 0 {
+		return result
+	}
+
+	if releaseA != "" && releaseB != "" {
+		return compareSegments(releaseA, releaseB)
+	}
+
+	return 0
+}
+
+func splitVersion(fullVersion string) (epoch string, version string, release string) {
+	epoch = "0"
+	version = fullVersion
+
+	if before, after, found := strings.Cut(version, ":"); found && isAllDigits(before) {
+		if before != "" {
+			epoch = before
+		}
+
+		version = after
+	}
+
+	if idx := strings.LastIndex(version, "-"); idx >= 0 {
+		release = version[idx+1:]
+		version = version[:idx]
+	}
+
+	return epoch, version, release
+}
+
+// rpmvercmp: alternating runs of digits and letters are compared pairwise, separators only count by length
+func compareSegments(a string, b string) int {
+	if a == b {
+		return 0
+	}
+
+	i, j := 0, 0
+
+	for i < len(a) && j < len(b) {
+		sepStartA, sepStartB := i, j
+
+		for i < len(a) && !isAlphaNum(a[i]) {
+			i++
+		}
+
+		for j < len(b) && !isAlphaNum(b[j]) {
+			j++
+		}
+
+		if i >= len(a) || j >= len(b) {
+			break
+		}
+
+		if i-sepStartA != j-sepStartB {
+			if i-sepStartA < j-sepStartB {
+				return -1
+			}
+
+			return 1
+		}
+
+		segStartA, segStartB := i, j
+		isNum := isDigit(a[i])
+
+		if isNum {
+			for i < len(a) && isDigit(a[i]) {
+				i++
+			}
+
+			for j < len(b) && isDigit(b[j]) {
+				j++
+			}
+		} else {
+			for i < len(a) && isAlpha(a[i]) {
+				i++
+			}
+
+			for j < len(b) && isAlpha(b[j]) {
+				j++
+			}
+		}
+
+		segA, segB := a[segStartA:i], b[segStartB:j]
+
+		// numeric segments are newer than alphabetic ones
+		if segB == "" {
+			if isNum {
+				return 1
+			}
+
+			return -1
+		}
+
+		if isNum {
+			segA = strings.TrimLeft(segA, "0")
+			segB = strings.TrimLeft(segB, "0")
+
+			if len(segA) != len(segB) {
+				if len(segA) > len(segB) {
+					return 1
+				}
+
+				return -1
+			}
+		}
+
+		if result := strings.Compare(segA, segB); result != 0 {
+			return result
+		}
+	}
+
+	if i >= len(a) && j >= len(b) {
+		return 0
+	}
+
+	// a trailing letter segment is older (1.0alpha < 1.0), anything else is newer (1.0.1 > 1.0)
+	if (i >= len(a) && !isAlpha(b[j])) || (i < len(a) && isAlpha(a[i])) {
+		return -1
+	}
+
+	return 1
+}
+
+func isAllDigits(value string) bool {
+	for i := range value {
+		if !isDigit(value[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isDigit(char byte) bool {
+	return char >= '0' && char <= '9'
+}
+
+func isAlpha(char byte) bool {
+	return (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z')
+}
+
+func isAlphaNum(char byte) bool {
+	return isDigit(char) || isAlpha(char)
+}
@@ -0,0 +1,28 @@
+package pkgdata
+
+import "testing"
+
+func TestCompareVersions(t *testing.T) {
+	cases := []struct {
+		a        string
+		b        string
+		expected int
+	}{
+		{"1.0", "1.0", 0},
+		{"1.10", "1.9", 1},
+		{"1.0.1", "1.0", 1},
+		{"1.0", "1.0a", 1},
+		{"1.0alpha", "1.0beta", -1},
+		{"1.001", "1.1", 0},
+		{"1.0-2", "1.0-10", -1},
+		{"1.0", "1.0-1", 0}, // a missing release matches any release
+		{"1:1.0", "2.0", 1},
+		{"2:6.1.1-3", "1:7.0-1", 1},
+	}
+
+	for _, c := range cases {
+		if result := CompareVersions(c.a, c.b); result != c.expected {
+			t.Errorf("CompareVersions(%q, %q): expected %d, got %d", c.a, c.b, c.expected, result)
+		}
+	}
+}
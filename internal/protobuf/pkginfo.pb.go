@@ -152,10 +152,7 @@ type PkgInfo struct {
 	License       string                 `protobuf:"bytes,7,opt,name=license,proto3" json:"license,omitempty"`
 	Url           string                 `protobuf:"bytes,8,opt,name=url,proto3" json:"url,omitempty"`
 	Description   string                 `protobuf:"bytes,13,opt,name=description,proto3" json:"description,omitempty"`
-	Depends       []*Relation            `protobuf:"bytes,9,rep,name=depends,proto3" json:"depends,omitempty"`
-	RequiredBy    []*Relation            `protobuf:"bytes,10,rep,name=required_by,json=requiredBy,proto3" json:"required_by,omitempty"`
-	Provides      []*Relation            `protobuf:"bytes,11,rep,name=provides,proto3" json:"provides,omitempty"`
-	Conflicts     []*Relation            `protobuf:"bytes,12,rep,name=conflicts,proto3" json:"conflicts,omitempty"`
+	DescModTime   int64                  `protobuf:"varint,14,opt,name=desc_mod_time,json=descModTime,proto3" json:"desc_mod_time,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -253,46 +250,139 @@ func (x *PkgInfo) GetDescription() string {
 	return ""
 }
 
-func (x *PkgInfo) GetDepends() []*Relation {
+func (x *PkgInfo) GetDescModTime() int64 {
+	if x != nil {
+		return x.DescModTime
+	}
+	return 0
+}
+
+type PkgRelations struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Depends       []*Relation            `protobuf:"bytes,1,rep,name=depends,proto3" json:"depends,omitempty"`
+	RequiredBy    []*Relation            `protobuf:"bytes,2,rep,name=required_by,json=requiredBy,proto3" json:"required_by,omitempty"`
+	Provides      []*Relation            `protobuf:"bytes,3,rep,name=provides,proto3" json:"provides,omitempty"`
+	Conflicts     []*Relation            `protobuf:"bytes,4,rep,name=conflicts,proto3" json:"conflicts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PkgRelations) Reset() {
+	*x = PkgRelations{}
+	mi := &file_protobuf_pkginfo_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PkgRelations) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PkgRelations) ProtoMessage() {}
+
+func (x *PkgRelations) ProtoReflect() protoreflect.Message {
+	mi := &file_protobuf_pkginfo_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PkgRelations.ProtoReflect.Descriptor instead.
+func (*PkgRelations) Descriptor() ([]byte, []int) {
+	return file_protobuf_pkginfo_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *PkgRelations) GetDepends() []*Relation {
 	if x != nil {
 		return x.Depends
 	}
 	return nil
 }
 
-func (x *PkgInfo) GetRequiredBy() []*Relation {
+func (x *PkgRelations) GetRequiredBy() []*Relation {
 	if x != nil {
 		return x.RequiredBy
 	}
 	return nil
 }
 
-func (x *PkgInfo) GetProvides() []*Relation {
+func (x *PkgRelations) GetProvides() []*Relation {
 	if x != nil {
 		return x.Provides
 	}
 	return nil
 }
 
-func (x *PkgInfo) GetConflicts() []*Relation {
+func (x *PkgRelations) GetConflicts() []*Relation {
 	if x != nil {
 		return x.Conflicts
 	}
 	return nil
 }
 
+type CachedRelations struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Pkgs          []*PkgRelations        `protobuf:"bytes,1,rep,name=pkgs,proto3" json:"pkgs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CachedRelations) Reset() {
+	*x = CachedRelations{}
+	mi := &file_protobuf_pkginfo_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CachedRelations) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CachedRelations) ProtoMessage() {}
+
+func (x *CachedRelations) ProtoReflect() protoreflect.Message {
+	mi := &file_protobuf_pkginfo_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CachedRelations.ProtoReflect.Descriptor instead.
+func (*CachedRelations) Descriptor() ([]byte, []int) {
+	return file_protobuf_pkginfo_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CachedRelations) GetPkgs() []*PkgRelations {
+	if x != nil {
+		return x.Pkgs
+	}
+	return nil
+}
+
 type CachedPkgs struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	LastModified  int64                  `protobuf:"varint,1,opt,name=last_modified,json=lastModified,proto3" json:"last_modified,omitempty"`
 	Pkgs          []*PkgInfo             `protobuf:"bytes,2,rep,name=pkgs,proto3" json:"pkgs,omitempty"`
 	Version       int32                  `protobuf:"varint,3,opt,name=version,proto3" json:"version,omitempty"`
+	DerivedData   int32                  `protobuf:"varint,4,opt,name=derived_data,json=derivedData,proto3" json:"derived_data,omitempty"`
+	Relations     []byte                 `protobuf:"bytes,5,opt,name=relations,proto3" json:"relations,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *CachedPkgs) Reset() {
 	*x = CachedPkgs{}
-	mi := &file_protobuf_pkginfo_proto_msgTypes[2]
+	mi := &file_protobuf_pkginfo_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -304,7 +394,7 @@ func (x *CachedPkgs) String() string {
 func (*CachedPkgs) ProtoMessage() {}
 
 func (x *CachedPkgs) ProtoReflect() protoreflect.Message {
-	mi := &file_protobuf_pkginfo_proto_msgTypes[2]
+	mi := &file_protobuf_pkginfo_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -317,7 +407,7 @@ func (x *CachedPkgs) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CachedPkgs.ProtoReflect.Descriptor instead.
 func (*CachedPkgs) Descriptor() ([]byte, []int) {
-	return file_protobuf_pkginfo_proto_rawDescGZIP(), []int{2}
+	return file_protobuf_pkginfo_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *CachedPkgs) GetLastModified() int64 {
@@ -341,6 +431,148 @@ func (x *CachedPkgs) GetVersion() int32 {
 	return 0
 }
 
+func (x *CachedPkgs) GetDerivedData() int32 {
+	if x != nil {
+		return x.DerivedData
+	}
+	return 0
+}
+
+func (x *CachedPkgs) GetRelations() []byte {
+	if x != nil {
+		return x.Relations
+	}
+	return nil
+}
+
+type SnapshotPkg struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Version       string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	Size          int64                  `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`
+	Repo          string                 `protobuf:"bytes,5,opt,name=repo,proto3" json:"repo,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SnapshotPkg) Reset() {
+	*x = SnapshotPkg{}
+	mi := &file_protobuf_pkginfo_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SnapshotPkg) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SnapshotPkg) ProtoMessage() {}
+
+func (x *SnapshotPkg) ProtoReflect() protoreflect.Message {
+	mi := &file_protobuf_pkginfo_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SnapshotPkg.ProtoReflect.Descriptor instead.
+func (*SnapshotPkg) Descriptor() ([]byte, []int) {
+	return file_protobuf_pkginfo_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *SnapshotPkg) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SnapshotPkg) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *SnapshotPkg) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *SnapshotPkg) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *SnapshotPkg) GetRepo() string {
+	if x != nil {
+		return x.Repo
+	}
+	return ""
+}
+
+type Snapshot struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Timestamp     int64                  `protobuf:"varint,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Pkgs          []*SnapshotPkg         `protobuf:"bytes,2,rep,name=pkgs,proto3" json:"pkgs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Snapshot) Reset() {
+	*x = Snapshot{}
+	mi := &file_protobuf_pkginfo_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Snapshot) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Snapshot) ProtoMessage() {}
+
+func (x *Snapshot) ProtoReflect() protoreflect.Message {
+	mi := &file_protobuf_pkginfo_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Snapshot.ProtoReflect.Descriptor instead.
+func (*Snapshot) Descriptor() ([]byte, []int) {
+	return file_protobuf_pkginfo_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Snapshot) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *Snapshot) GetPkgs() []*SnapshotPkg {
+	if x != nil {
+		return x.Pkgs
+	}
+	return nil
+}
+
 var File_protobuf_pkginfo_proto protoreflect.FileDescriptor
 
 const file_protobuf_pkginfo_proto_rawDesc = "" +
@@ -349,7 +581,7 @@ const file_protobuf_pkginfo_proto_rawDesc = "" +
 	"\bRelation\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
 	"\aversion\x18\x02 \x01(\tR\aversion\x12/\n" +
-	"\boperator\x18\x03 \x01(\x0e2\x13.pkginfo.RelationOpR\boperator\"\xa4\x03\n" +
+	"\boperator\x18\x03 \x01(\x0e2\x13.pkginfo.RelationOpR\boperator\"\x87\x02\n" +
 	"\aPkgInfo\x12\x1c\n" +
 	"\ttimestamp\x18\x01 \x01(\x03R\ttimestamp\x12\x12\n" +
 	"\x04size\x18\x02 \x01(\x03R\x04size\x12\x12\n" +
@@ -359,18 +591,32 @@ const file_protobuf_pkginfo_proto_rawDesc = "" +
 	"\x04arch\x18\x06 \x01(\tR\x04arch\x12\x18\n" +
 	"\alicense\x18\a \x01(\tR\alicense\x12\x10\n" +
 	"\x03url\x18\b \x01(\tR\x03url\x12 \n" +
-	"\vdescription\x18\r \x01(\tR\vdescription\x12+\n" +
-	"\adepends\x18\t \x03(\v2\x11.pkginfo.RelationR\adepends\x122\n" +
-	"\vrequired_by\x18\n" +
-	" \x03(\v2\x11.pkginfo.RelationR\n" +
+	"\vdescription\x18\r \x01(\tR\vdescription\x12\"\n" +
+	"\rdesc_mod_time\x18\x0e \x01(\x03R\vdescModTime\"\xcf\x01\n" +
+	"\fPkgRelations\x12+\n" +
+	"\adepends\x18\x01 \x03(\v2\x11.pkginfo.RelationR\adepends\x122\n" +
+	"\vrequired_by\x18\x02 \x03(\v2\x11.pkginfo.RelationR\n" +
 	"requiredBy\x12-\n" +
-	"\bprovides\x18\v \x03(\v2\x11.pkginfo.RelationR\bprovides\x12/\n" +
-	"\tconflicts\x18\f \x03(\v2\x11.pkginfo.RelationR\tconflicts\"q\n" +
+	"\bprovides\x18\x03 \x03(\v2\x11.pkginfo.RelationR\bprovides\x12/\n" +
+	"\tconflicts\x18\x04 \x03(\v2\x11.pkginfo.RelationR\tconflicts\"<\n" +
+	"\x0fCachedRelations\x12)\n" +
+	"\x04pkgs\x18\x01 \x03(\v2\x15.pkginfo.PkgRelationsR\x04pkgs\"\xb2\x01\n" +
 	"\n" +
 	"CachedPkgs\x12#\n" +
 	"\rlast_modified\x18\x01 \x01(\x03R\flastModified\x12$\n" +
 	"\x04pkgs\x18\x02 \x03(\v2\x10.pkginfo.PkgInfoR\x04pkgs\x12\x18\n" +
-	"\aversion\x18\x03 \x01(\x05R\aversion*[\n" +
+	"\aversion\x18\x03 \x01(\x05R\aversion\x12!\n" +
+	"\fderived_data\x18\x04 \x01(\x05R\vderivedData\x12\x1c\n" +
+	"\trelations\x18\x05 \x01(\fR\trelations\"{\n" +
+	"\vSnapshotPkg\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12\x12\n" +
+	"\x04size\x18\x04 \x01(\x03R\x04size\x12\x12\n" +
+	"\x04repo\x18\x05 \x01(\tR\x04repo\"R\n" +
+	"\bSnapshot\x12\x1c\n" +
+	"\ttimestamp\x18\x01 \x01(\x03R\ttimestamp\x12(\n" +
+	"\x04pkgs\x18\x02 \x03(\v2\x14.pkginfo.SnapshotPkgR\x04pkgs*[\n" +
 	"\n" +
 	"RelationOp\x12\b\n" +
 	"\x04NONE\x10\x00\x12\t\n" +
@@ -394,25 +640,31 @@ func file_protobuf_pkginfo_proto_rawDescGZIP() []byte {
 }
 
 var file_protobuf_pkginfo_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_protobuf_pkginfo_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_protobuf_pkginfo_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
 var file_protobuf_pkginfo_proto_goTypes = []any{
-	(RelationOp)(0),    // 0: pkginfo.RelationOp
-	(*Relation)(nil),   // 1: pkginfo.Relation
-	(*PkgInfo)(nil),    // 2: pkginfo.PkgInfo
-	(*CachedPkgs)(nil), // 3: pkginfo.CachedPkgs
+	(RelationOp)(0),         // 0: pkginfo.RelationOp
+	(*Relation)(nil),        // 1: pkginfo.Relation
+	(*PkgInfo)(nil),         // 2: pkginfo.PkgInfo
+	(*PkgRelations)(nil),    // 3: pkginfo.PkgRelations
+	(*CachedRelations)(nil), // 4: pkginfo.CachedRelations
+	(*CachedPkgs)(nil),      // 5: pkginfo.CachedPkgs
+	(*SnapshotPkg)(nil),     // 6: pkginfo.SnapshotPkg
+	(*Snapshot)(nil),        // 7: pkginfo.Snapshot
 }
 var file_protobuf_pkginfo_proto_depIdxs = []int32{
 	0, // 0: pkginfo.Relation.operator:type_name -> pkginfo.RelationOp
-	1, // 1: pkginfo.PkgInfo.depends:type_name -> pkginfo.Relation
-	1, // 2: pkginfo.PkgInfo.required_by:type_name -> pkginfo.Relation
-	1, // 3: pkginfo.PkgInfo.provides:type_name -> pkginfo.Relation
-	1, // 4: pkginfo.PkgInfo.conflicts:type_name -> pkginfo.Relation
-	2, // 5: pkginfo.CachedPkgs.pkgs:type_name -> pkginfo.PkgInfo
-	6, // [6:6] is the sub-list for method output_type
-	6, // [6:6] is the sub-list for method input_type
-	6, // [6:6] is the sub-list for extension type_name
-	6, // [6:6] is the sub-list for extension extendee
-	0, // [0:6] is the sub-list for field type_name
+	1, // 1: pkginfo.PkgRelations.depends:type_name -> pkginfo.Relation
+	1, // 2: pkginfo.PkgRelations.required_by:type_name -> pkginfo.Relation
+	1, // 3: pkginfo.PkgRelations.provides:type_name -> pkginfo.Relation
+	1, // 4: pkginfo.PkgRelations.conflicts:type_name -> pkginfo.Relation
+	3, // 5: pkginfo.CachedRelations.pkgs:type_name -> pkginfo.PkgRelations
+	2, // 6: pkginfo.CachedPkgs.pkgs:type_name -> pkginfo.PkgInfo
+	6, // 7: pkginfo.Snapshot.pkgs:type_name -> pkginfo.SnapshotPkg
+	8, // [8:8] is the sub-list for method output_type
+	8, // [8:8] is the sub-list for method input_type
+	8, // [8:8] is the sub-list for extension type_name
+	8, // [8:8] is the sub-list for extension extendee
+	0, // [0:8] is the sub-list for field type_name
 }
 
 func init() { file_protobuf_pkginfo_proto_init() }
@@ -426,7 +678,7 @@ func file_protobuf_pkginfo_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_protobuf_pkginfo_proto_rawDesc), len(file_protobuf_pkginfo_proto_rawDesc)),
 			NumEnums:      1,
-			NumMessages:   3,
+			NumMessages:   7,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -0,0 +1,141 @@
+package timeexpr
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"yaylog/internal/consts"
+)
+
+// the stretch of time an expression refers to, [Start, End).
+// timestamps and relative times are instants, where Start equals End
+type Span struct {
+	Start      time.Time
+	End        time.Time
+	IsRelative bool // counted back from now, such as 7d
+}
+
+func (span Span) IsInstant() bool {
+	return span.Start.Equal(span.End)
+}
+
+// calendar forms, each covering a whole day, month or year
+var calendarFormats = []struct {
+	layout string
+	years  int
+	months int
+	days   int
+}{
+	{consts.DateOnlyFormat, 0, 0, 1},
+	{"2006-01", 0, 1, 0},
+	{"2006", 1, 0, 0},
+}
+
+var timestampFormats = []string{
+	time.RFC3339,
+	consts.DateTimeFormat,
+}
+
+// a count and unit, such as 7d or 2w, meaning that long ago
+var relativePattern = regexp.MustCompile(`^(\d+)(h|d|w|m|y)$`)
+
+const ExpressionHelp = "YYYY-MM-DD, YYYY-MM, YYYY, \"YYYY-MM-DD HH:MM:SS\", RFC3339, today, yesterday, or a time ago such as 12h, 7d, 2w, 3m or 1y"
+
+// resolves calendar dates, timestamps, today/yesterday and relative times ago, relative to now.
+// calendar dates are taken in loc unless a timestamp carries its own offset
+func Parse(input string, now time.Time, loc *time.Location) (Span, error) {
+	input = strings.ToLower(strings.TrimSpace(input))
+	now = now.In(loc)
+
+	switch input {
+	case "now":
+		return Span{now, now, true}, nil
+	case "today":
+		return daySpan(now, 0), nil
+	case "yesterday":
+		return daySpan(now, -1), nil
+	}
+
+	if matches := relativePattern.FindStringSubmatch(input); matches != nil {
+		count, err := strconv.Atoi(matches[1])
+		if err != nil {
+			return Span{}, fmt.Errorf("invalid relative time %q: %v", input, err)
+		}
+
+		ago := timeAgo(now, count, matches[2])
+		return Span{ago, ago, true}, nil
+	}
+
+	for _, format := range timestampFormats {
+		// RFC3339 is case sensitive about its T and Z
+		if parsedTime, err := time.ParseInLocation(format, strings.ToUpper(input), loc); err == nil {
+			return Span{Start: parsedTime, End: parsedTime}, nil
+		}
+	}
+
+	for _, format := range calendarFormats {
+		if parsedTime, err := time.ParseInLocation(format.layout, input, loc); err == nil {
+			return Span{Start: parsedTime, End: parsedTime.AddDate(format.years, format.months, format.days)}, nil
+		}
+	}
+
+	return Span{}, fmt.Errorf("invalid date %q, expected %s", input, ExpressionHelp)
+}
+
+func daySpan(now time.Time, offsetDays int) Span {
+	start := time.Date(now.Year(), now.Month(), now.Day()+offsetDays, 0, 0, 0, 0, now.Location())
+	return Span{Start: start, End: start.AddDate(0, 0, 1)}
+}
+
+func timeAgo(now time.Time, count int, unit string) time.Time {
+	switch unit {
+	case "h":
+		return now.Add(-time.Duration(count) * time.Hour)
+	case "d":
+		return now.AddDate(0, 0, -count)
+	case "w":
+		return now.AddDate(0, 0, -7*count)
+	case "m":
+		return now.AddDate(0, -count, 0)
+	default:
+		return now.AddDate(-count, 0, 0)
+	}
+}
+
+// splits start:end at the colon that leaves two valid expressions, timestamps contain colons too.
+// ok is false for a single expression
+func SplitRange(input string, now time.Time, loc *time.Location) (start string, end string, ok bool) {
+	if _, err := Parse(input, now, loc); err == nil {
+		return "", "", false
+	}
+
+	for i := 0; i < len(input); i++ {
+		if input[i] != ':' {
+			continue
+		}
+
+		start, end = input[:i], input[i+1:]
+		if isEmptyOrValid(start, now, loc) && isEmptyOrValid(end, now, loc) {
+			return start, end, true
+		}
+	}
+
+	// not a valid range either, the caller reports the whole input as invalid
+	if strings.Contains(input, ":") {
+		idx := strings.LastIndex(input, ":")
+		return input[:idx], input[idx+1:], true
+	}
+
+	return "", "", false
+}
+
+func isEmptyOrValid(input string, now time.Time, loc *time.Location) bool {
+	if input == "" {
+		return true
+	}
+
+	_, err := Parse(input, now, loc)
+	return err == nil
+}
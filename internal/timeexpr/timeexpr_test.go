@@ -0,0 +1,72 @@
+package timeexpr
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	now := time.Date(2024, 6, 15, 13, 30, 0, 0, time.UTC)
+	day := func(year int, month time.Month, dayOfMonth int) time.Time {
+		return time.Date(year, month, dayOfMonth, 0, 0, 0, 0, time.UTC)
+	}
+
+	cases := []struct {
+		input string
+		start time.Time
+		end   time.Time
+	}{
+		{"2024-05-01", day(2024, 5, 1), day(2024, 5, 2)},
+		{"2024-05", day(2024, 5, 1), day(2024, 6, 1)},
+		{"2024", day(2024, 1, 1), day(2025, 1, 1)},
+		{"today", day(2024, 6, 15), day(2024, 6, 16)},
+		{"Yesterday", day(2024, 6, 14), day(2024, 6, 15)},
+		{"7d", now.AddDate(0, 0, -7), now.AddDate(0, 0, -7)},
+		{"2w", now.AddDate(0, 0, -14), now.AddDate(0, 0, -14)},
+		{"12h", now.Add(-12 * time.Hour), now.Add(-12 * time.Hour)},
+		{"2024-05-01 10:00:00", day(2024, 5, 1).Add(10 * time.Hour), day(2024, 5, 1).Add(10 * time.Hour)},
+		{"2024-05-01T10:00:00+02:00", day(2024, 5, 1).Add(8 * time.Hour), day(2024, 5, 1).Add(8 * time.Hour)},
+	}
+
+	for _, c := range cases {
+		span, err := Parse(c.input, now, time.UTC)
+		if err != nil {
+			t.Errorf("Parse(%q): unexpected error: %v", c.input, err)
+			continue
+		}
+
+		if !span.Start.Equal(c.start) || !span.End.Equal(c.end) {
+			t.Errorf("Parse(%q): expected [%v, %v), got [%v, %v)", c.input, c.start, c.end, span.Start, span.End)
+		}
+	}
+
+	for _, input := range []string{"", "7", "7x", "2024-13", "last week"} {
+		if _, err := Parse(input, now, time.UTC); err == nil {
+			t.Errorf("Parse(%q): expected an error", input)
+		}
+	}
+}
+
+func TestSplitRange(t *testing.T) {
+	now := time.Date(2024, 6, 15, 13, 30, 0, 0, time.UTC)
+
+	cases := []struct {
+		input   string
+		start   string
+		end     string
+		isRange bool
+	}{
+		{"2024-05-01", "", "", false},
+		{"2024-05-01T10:00:00Z", "", "", false},
+		{"7d:", "7d", "", true},
+		{":2w", "", "2w", true},
+		{"2024-05-01T10:00:00Z:today", "2024-05-01T10:00:00Z", "today", true},
+	}
+
+	for _, c := range cases {
+		start, end, isRange := SplitRange(c.input, now, time.UTC)
+		if start != c.start || end != c.end || isRange != c.isRange {
+			t.Errorf("SplitRange(%q): expected %q, %q, %t, got %q, %q, %t", c.input, c.start, c.end, c.isRange, start, end, isRange)
+		}
+	}
+}